@@ -0,0 +1,245 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// pub.dev archive limits.
+const (
+	// DefaultMaxArchiveSize is the largest compressed archive pub.dev accepts.
+	DefaultMaxArchiveSize = 100 << 20
+)
+
+// DefaultForbiddenPatterns lists files that should never be published.
+var DefaultForbiddenPatterns = []string{
+	"*.env",
+	"build/",
+	"*.jks",
+	"*.keystore",
+	"*.p12",
+	"*.pfx",
+	"key.properties",
+}
+
+// basicIgnoreRules are the rules pub applies at the package root before any
+// .pubignore or .gitignore: hidden files except .htaccess, packages
+// directories, lockfiles and the root pubspec_overrides.yaml.
+var basicIgnoreRules = []string{
+	".*",
+	"!.htaccess",
+	"packages/",
+	"pubspec.lock",
+	"!pubspec.lock/",
+	"/pubspec_overrides.yaml",
+}
+
+// PackageFile is a file that would be included in the published archive.
+type PackageFile struct {
+	// Path is slash-separated and relative to the package root.
+	Path string
+	Size int64
+}
+
+// ArchiveReport summarizes the archive that would be uploaded.
+type ArchiveReport struct {
+	Files          []PackageFile
+	TotalSize      int64
+	CompressedSize int64
+	SHA256         string
+}
+
+// ListPackageFiles returns the files pub would publish from dir. Pub's basic
+// ignore rules and the exclude patterns apply from the package root, and
+// .pubignore replaces .gitignore in the directory that contains it.
+func ListPackageFiles(dir string, exclude []string) ([]PackageFile, error) {
+	rules := newIgnoreRules("", append(slices.Clone(basicIgnoreRules), exclude...))
+	rulesByDir := map[string]ignoreRules{}
+
+	var files []PackageFile
+	err := filepath.WalkDir(dir, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if rel == "." {
+			rulesByDir["."] = append(slices.Clone(rules), dirIgnoreRules(p, "")...)
+			return nil
+		}
+
+		parent := filepath.ToSlash(filepath.Dir(rel))
+		active := rulesByDir[parent]
+
+		if active.ignored(rel, entry.IsDir()) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if entry.IsDir() {
+			rulesByDir[rel] = append(slices.Clone(active), dirIgnoreRules(p, rel)...)
+			return nil
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, PackageFile{Path: rel, Size: info.Size()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list package files: %w", err)
+	}
+
+	return files, nil
+}
+
+// dirIgnoreRules loads the ignore rules declared in a directory.
+func dirIgnoreRules(dir, base string) ignoreRules {
+	if lines, ok := readIgnoreFile(filepath.Join(dir, ".pubignore")); ok {
+		return newIgnoreRules(base, lines)
+	}
+	lines, _ := readIgnoreFile(filepath.Join(dir, ".gitignore"))
+	return newIgnoreRules(base, lines)
+}
+
+// BuildArchiveReport writes the files into a gzipped tarball in memory to
+// measure its compressed size and checksum.
+func BuildArchiveReport(dir string, files []PackageFile) (*ArchiveReport, error) {
+	counter := &countingWriter{}
+	hash := sha256.New()
+
+	gz := gzip.NewWriter(io.MultiWriter(counter, hash))
+	tw := tar.NewWriter(gz)
+
+	report := &ArchiveReport{Files: files}
+	for _, f := range files {
+		if err := addToArchive(tw, dir, f); err != nil {
+			return nil, err
+		}
+		report.TotalSize += f.Size
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finalize archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finalize archive: %w", err)
+	}
+
+	report.CompressedSize = counter.n
+	report.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	return report, nil
+}
+
+func addToArchive(tw *tar.Writer, dir string, f PackageFile) error {
+	in, err := os.Open(filepath.Join(dir, filepath.FromSlash(f.Path)))
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", f.Path, err)
+	}
+	defer func() { _ = in.Close() }()
+
+	header := &tar.Header{
+		Name: f.Path,
+		Mode: 0644,
+		Size: f.Size,
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to archive %s: %w", f.Path, err)
+	}
+	if _, err := io.CopyN(tw, in, f.Size); err != nil {
+		return fmt.Errorf("failed to archive %s: %w", f.Path, err)
+	}
+	return nil
+}
+
+// EvaluateArchive checks the report against the configured limits and
+// returns a description of every violation.
+func EvaluateArchive(report *ArchiveReport, cfg ArchiveConfig) []string {
+	var failures []string
+
+	if cfg.MaxSize > 0 && report.CompressedSize > cfg.MaxSize {
+		failures = append(failures, fmt.Sprintf("archive is %s compressed, exceeding the %s limit",
+			formatBytes(report.CompressedSize), formatBytes(cfg.MaxSize)))
+	}
+
+	forbidden := newIgnoreRules("", cfg.ForbiddenPatterns)
+	for _, f := range report.Files {
+		if cfg.MaxFileSize > 0 && f.Size > cfg.MaxFileSize {
+			failures = append(failures, fmt.Sprintf("%s is %s, exceeding the %s per-file limit",
+				f.Path, formatBytes(f.Size), formatBytes(cfg.MaxFileSize)))
+		}
+		if forbidden.matchesPathOrParent(f.Path) {
+			failures = append(failures, fmt.Sprintf("%s matches a forbidden pattern", f.Path))
+		}
+	}
+
+	return failures
+}
+
+// FormatArchiveSummary renders the archive size and its largest files.
+func FormatArchiveSummary(report *ArchiveReport, largest int) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Archive: %d files, %s uncompressed, %s compressed\n",
+		len(report.Files), formatBytes(report.TotalSize), formatBytes(report.CompressedSize)))
+
+	files := slices.Clone(report.Files)
+	slices.SortStableFunc(files, func(a, b PackageFile) int {
+		switch {
+		case a.Size > b.Size:
+			return -1
+		case a.Size < b.Size:
+			return 1
+		default:
+			return strings.Compare(a.Path, b.Path)
+		}
+	})
+	for _, f := range files[:max(0, min(largest, len(files)))] {
+		sb.WriteString(fmt.Sprintf("  %10s  %s\n", formatBytes(f.Size), f.Path))
+	}
+
+	return sb.String()
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+// countingWriter counts the bytes written to it.
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
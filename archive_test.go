@@ -0,0 +1,233 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestListPackageFiles(t *testing.T) {
+	dir := t.TempDir()
+	writePackageFiles(t, dir, map[string]string{
+		"pubspec.yaml":              "name: my_package\n",
+		"pubspec_overrides.yaml":    "dependency_overrides: {}\n",
+		"README.md":                 "# my_package\n",
+		"lib/my_package.dart":       "library my_package;\n",
+		"lib/src/model.g.dart":      "// generated\n",
+		"lib/.hidden.dart":          "// hidden\n",
+		".dart_tool/package_config": "{}",
+		".env":                      "SECRET=1\n",
+		".gitignore":                "build/\n*.log\n",
+		"build/app.js":              "// build\n",
+		"debug.log":                 "log\n",
+		"test/.gitignore":           "fixtures/\n",
+		"test/fixtures/data.json":   "{}",
+		"test/my_package_test.dart": "void main() {}\n",
+		"example/.pubignore":        "",
+		"example/.gitignore":        "*.dart\n",
+		"example/main.dart":         "void main() {}\n",
+		"tool/release.sh":           "#!/bin/sh\n",
+		"doc/.pubignore":            "*.psd\n!keep.psd\n",
+		"doc/design.psd":            "psd",
+		"doc/keep.psd":              "psd",
+	})
+
+	files, err := ListPackageFiles(dir, []string{"tool/"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, f := range files {
+		got = append(got, f.Path)
+	}
+
+	expected := []string{
+		"README.md",
+		"doc/keep.psd",
+		"example/main.dart",
+		"lib/my_package.dart",
+		"lib/src/model.g.dart",
+		"pubspec.yaml",
+		"test/my_package_test.dart",
+	}
+	if strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected files:\n%v\ngot:\n%v", expected, got)
+	}
+}
+
+func TestListPackageFiles_BasicIgnoreRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  []string
+	}{
+		{
+			name:  "hidden files and directories",
+			files: []string{".env", "lib/.hidden.dart", ".dart_tool/package_config.json", "lib/a.dart"},
+			want:  []string{"lib/a.dart"},
+		},
+		{
+			name:  "htaccess is kept",
+			files: []string{".htaccess", "web/.htaccess", "web/.htpasswd"},
+			want:  []string{".htaccess", "web/.htaccess"},
+		},
+		{
+			name:  "packages directories",
+			files: []string{"packages/x.dart", "lib/packages/y.dart", "lib/packages.dart"},
+			want:  []string{"lib/packages.dart"},
+		},
+		{
+			name:  "lockfiles at any depth",
+			files: []string{"pubspec.lock", "example/pubspec.lock", "lib/a.dart"},
+			want:  []string{"lib/a.dart"},
+		},
+		{
+			name:  "directories named pubspec.lock are kept",
+			files: []string{"test/pubspec.lock/fixture.txt"},
+			want:  []string{"test/pubspec.lock/fixture.txt"},
+		},
+		{
+			name:  "overrides only at the root",
+			files: []string{"pubspec_overrides.yaml", "example/pubspec_overrides.yaml"},
+			want:  []string{"example/pubspec_overrides.yaml"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			contents := map[string]string{}
+			for _, f := range tt.files {
+				contents[f] = "x"
+			}
+			writePackageFiles(t, dir, contents)
+
+			files, err := ListPackageFiles(dir, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, f := range files {
+				got = append(got, f.Path)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBuildArchiveReport(t *testing.T) {
+	dir := t.TempDir()
+	writePackageFiles(t, dir, map[string]string{
+		"pubspec.yaml":        "name: my_package\n",
+		"lib/my_package.dart": strings.Repeat("// padding\n", 100),
+	})
+
+	files, err := ListPackageFiles(dir, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	report, err := BuildArchiveReport(dir, files)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if report.TotalSize != int64(len("name: my_package\n")+1100) {
+		t.Errorf("unexpected total size %d", report.TotalSize)
+	}
+	if report.CompressedSize == 0 || report.CompressedSize >= report.TotalSize+2048 {
+		t.Errorf("unexpected compressed size %d", report.CompressedSize)
+	}
+	if len(report.SHA256) != 64 {
+		t.Errorf("expected sha256 hex digest, got %q", report.SHA256)
+	}
+
+	again, err := BuildArchiveReport(dir, files)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if again.SHA256 != report.SHA256 {
+		t.Error("expected archive checksum to be deterministic")
+	}
+}
+
+func TestEvaluateArchive(t *testing.T) {
+	report := &ArchiveReport{
+		Files: []PackageFile{
+			{Path: "lib/main.dart", Size: 1000},
+			{Path: "android/app/upload.jks", Size: 2000},
+			{Path: "build/web/main.dart.js", Size: 5000},
+			{Path: "assets/video.mp4", Size: 50000},
+		},
+		TotalSize:      58000,
+		CompressedSize: 40000,
+	}
+
+	tests := []struct {
+		name     string
+		cfg      ArchiveConfig
+		contains []string
+	}{
+		{
+			name: "no limits",
+			cfg:  ArchiveConfig{},
+		},
+		{
+			name: "defaults",
+			cfg: ArchiveConfig{
+				MaxSize:           DefaultMaxArchiveSize,
+				ForbiddenPatterns: DefaultForbiddenPatterns,
+			},
+			contains: []string{"android/app/upload.jks", "build/web/main.dart.js"},
+		},
+		{
+			name:     "size limits",
+			cfg:      ArchiveConfig{MaxSize: 30000, MaxFileSize: 10000},
+			contains: []string{"archive is 39.1 KB compressed", "assets/video.mp4 is 48.8 KB"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failures := EvaluateArchive(report, tt.cfg)
+			if len(failures) != len(tt.contains) {
+				t.Fatalf("expected %d failures, got %v", len(tt.contains), failures)
+			}
+			for i, want := range tt.contains {
+				if !strings.Contains(failures[i], want) {
+					t.Errorf("expected failure %d to contain %q, got %q", i, want, failures[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFormatArchiveSummary(t *testing.T) {
+	report := &ArchiveReport{
+		Files: []PackageFile{
+			{Path: "a.dart", Size: 10},
+			{Path: "b.dart", Size: 3000},
+			{Path: "c.dart", Size: 2 << 20},
+		},
+		TotalSize:      2<<20 + 3010,
+		CompressedSize: 1 << 20,
+	}
+
+	summary := FormatArchiveSummary(report, 2)
+	lines := strings.Split(strings.TrimSpace(summary), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected header and 2 files, got:\n%s", summary)
+	}
+	if !strings.Contains(lines[0], "3 files") || !strings.Contains(lines[0], "1.0 MB compressed") {
+		t.Errorf("unexpected header %q", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], "c.dart") || !strings.HasSuffix(lines[2], "b.dart") {
+		t.Errorf("expected largest files first, got:\n%s", summary)
+	}
+
+	if summary := FormatArchiveSummary(report, -1); strings.Count(summary, "\n") != 1 {
+		t.Errorf("expected only the header for a negative limit, got:\n%s", summary)
+	}
+}
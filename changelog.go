@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ChangelogFile is the changelog file name shown by pub.dev.
+const ChangelogFile = "CHANGELOG.md"
+
+// changelogHeading returns a pattern matching a heading for version, such as
+// "## 1.2.0", "## [1.2.0] - 2024-01-01" or "# v1.2.0".
+func changelogHeading(version string) *regexp.Regexp {
+	return regexp.MustCompile(`(?m)^#{1,3}\s*\[?v?` + regexp.QuoteMeta(version) + `\]?(\s|$)`)
+}
+
+// ChangelogHasVersion reports whether the changelog at path has a heading for version.
+func ChangelogHasVersion(path, version string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to read %s: %w", ChangelogFile, err)
+	}
+
+	return changelogHeading(version).Match(data), nil
+}
+
+// PrependChangelogEntry adds an entry for version above the existing entries,
+// keeping a leading title and any intro text in place. The file is created if
+// it does not exist.
+func PrependChangelogEntry(path, version, body string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", ChangelogFile, err)
+	}
+
+	entry := FormatChangelogEntry(version, body)
+	content := string(data)
+
+	var updated string
+	switch idx := firstEntryIndex(content); {
+	case strings.TrimSpace(content) == "":
+		updated = entry
+	case idx >= 0:
+		updated = content[:idx] + entry + "\n" + content[idx:]
+	default:
+		updated = strings.TrimRight(content, "\n") + "\n\n" + entry
+	}
+
+	if err := os.WriteFile(path, []byte(updated), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", ChangelogFile, err)
+	}
+
+	return nil
+}
+
+// notesHeading returns a pattern matching a heading of any level that names
+// version anywhere, such as "# [1.2.0](https://example.com/compare) (2024-01-01)".
+func notesHeading(version string) *regexp.Regexp {
+	return regexp.MustCompile(`^#{1,6}\s+(?:.*?[^\w.+-])?v?` + regexp.QuoteMeta(version) + `(?:[^\w.+-]|$)`)
+}
+
+// FormatChangelogEntry builds a changelog section for version from release notes.
+// A leading heading in the notes that names the version is dropped so the
+// entry does not repeat it.
+func FormatChangelogEntry(version, notes string) string {
+	notes = strings.TrimSpace(notes)
+
+	if first, rest, _ := strings.Cut(notes, "\n"); notesHeading(version).MatchString(first) {
+		notes = strings.TrimSpace(rest)
+	}
+	if notes == "" {
+		notes = fmt.Sprintf("- Release %s", version)
+	}
+
+	return fmt.Sprintf("## %s\n\n%s\n", version, notes)
+}
+
+// firstEntryIndex returns the offset of the first version heading (level 2 or
+// deeper, or any level-1 heading after the title), or -1 if there is none.
+func firstEntryIndex(content string) int {
+	offset := 0
+	sawTitle := false
+	for _, line := range strings.SplitAfter(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "## "), strings.HasPrefix(trimmed, "### "):
+			return offset
+		case strings.HasPrefix(trimmed, "# "):
+			if sawTitle || !strings.Contains(strings.ToLower(trimmed), "changelog") {
+				return offset
+			}
+			sawTitle = true
+		}
+		offset += len(line)
+	}
+	return -1
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestChangelogHasVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		version string
+		want    bool
+	}{
+		{"plain heading", "# Changelog\n\n## 1.2.0\n\n- Fix\n", "1.2.0", true},
+		{"keep a changelog", "## [1.2.0] - 2024-12-19\n", "1.2.0", true},
+		{"v prefix", "# v1.2.0\n", "1.2.0", true},
+		{"build number", "## 1.2.0+3\n", "1.2.0+3", true},
+		{"different version", "## 1.2.1\n", "1.2.0", false},
+		{"version prefix only", "## 1.2.0-dev.1\n", "1.2.0", false},
+		{"mentioned in body", "## 1.1.0\n\n- Prepare 1.2.0\n", "1.2.0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ChangelogFile)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to write file: %v", err)
+			}
+
+			got, err := ChangelogHasVersion(path, tt.version)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	got, err := ChangelogHasVersion(filepath.Join(t.TempDir(), ChangelogFile), "1.0.0")
+	if err != nil || got {
+		t.Errorf("expected missing changelog to report false without error, got %v, %v", got, err)
+	}
+}
+
+func TestFormatChangelogEntry(t *testing.T) {
+	tests := []struct {
+		name  string
+		notes string
+		want  string
+	}{
+		{"plain heading", "## 2.0.0\n\n- Added feature", "## 2.0.0\n\n- Added feature\n"},
+		{"keep a changelog heading", "## [2.0.0] - 2024-12-19\n\n- Added feature", "## 2.0.0\n\n- Added feature\n"},
+		{"conventional changelog link", "# [2.0.0](https://github.com/o/r/compare/v1.9.0...v2.0.0) (2024-12-19)\n\n### Features\n\n- Added feature",
+			"## 2.0.0\n\n### Features\n\n- Added feature\n"},
+		{"v prefix at level 4", "#### Release v2.0.0\n- Added feature", "## 2.0.0\n\n- Added feature\n"},
+		{"heading for another version", "## 2.0.0-dev.1\n\n- Added feature", "## 2.0.0\n\n## 2.0.0-dev.1\n\n- Added feature\n"},
+		{"heading without the version", "### Features\n\n- Added feature", "## 2.0.0\n\n### Features\n\n- Added feature\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatChangelogEntry("2.0.0", tt.notes); got != tt.want {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPrependChangelogEntry(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  *string
+		notes    string
+		expected string
+	}{
+		{
+			name:     "missing file",
+			content:  nil,
+			notes:    "- Added feature",
+			expected: "## 2.0.0\n\n- Added feature\n",
+		},
+		{
+			name:     "title and entries",
+			content:  strPtr("# Changelog\n\nAll notable changes.\n\n## 1.0.0\n\n- Initial release\n"),
+			notes:    "- Added feature",
+			expected: "# Changelog\n\nAll notable changes.\n\n## 2.0.0\n\n- Added feature\n\n## 1.0.0\n\n- Initial release\n",
+		},
+		{
+			name:     "entries only",
+			content:  strPtr("## 1.0.0\n\n- Initial release\n"),
+			notes:    "",
+			expected: "## 2.0.0\n\n- Release 2.0.0\n\n## 1.0.0\n\n- Initial release\n",
+		},
+		{
+			name:     "title only",
+			content:  strPtr("# Changelog\n"),
+			notes:    "## [2.0.0] - 2024-12-19\n\n### Features\n\n- Added feature\n",
+			expected: "# Changelog\n\n## 2.0.0\n\n### Features\n\n- Added feature\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ChangelogFile)
+			if tt.content != nil {
+				if err := os.WriteFile(path, []byte(*tt.content), 0644); err != nil {
+					t.Fatalf("failed to write file: %v", err)
+				}
+			}
+
+			if err := PrependChangelogEntry(path, "2.0.0", tt.notes); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read file: %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("expected:\n%s\n\ngot:\n%s", tt.expected, string(data))
+			}
+
+			has, err := ChangelogHasVersion(path, "2.0.0")
+			if err != nil || !has {
+				t.Errorf("expected changelog to contain 2.0.0 after prepending, got %v, %v", has, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"sort"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/helpers"
+)
+
+// DefaultCommandTimeout limits a publish command that sets no timeout.
+const DefaultCommandTimeout = 10 * time.Minute
+
+// CommandConfig is a shell command run before or after publishing.
+type CommandConfig struct {
+	Run string `json:"run"`
+	// Env holds extra environment variables. Values are templates with the
+	// same fields as git_commit_config.message.
+	Env map[string]string `json:"env"`
+	// Timeout is a duration such as "90s" or "5m".
+	Timeout         string `json:"timeout"`
+	ContinueOnError bool   `json:"continue_on_error"`
+}
+
+// timeout returns the command timeout, or DefaultCommandTimeout if unset
+// or invalid.
+func (c CommandConfig) timeout() time.Duration {
+	if d := parsePositiveDuration(c.Timeout); d > 0 {
+		return d
+	}
+	return DefaultCommandTimeout
+}
+
+// parseCommands parses a command list. Entries are either a command line
+// or a map with run, env, timeout and continue_on_error.
+func parseCommands(raw any) []CommandConfig {
+	items, ok := raw.([]any)
+	if !ok {
+		return nil
+	}
+
+	var cmds []CommandConfig
+	for _, item := range items {
+		switch v := item.(type) {
+		case string:
+			cmds = append(cmds, CommandConfig{Run: v})
+		case map[string]any:
+			cmdParser := helpers.NewConfigParser(v)
+			cmd := CommandConfig{
+				Run:             cmdParser.GetString("run", "", ""),
+				Timeout:         cmdParser.GetString("timeout", "", ""),
+				ContinueOnError: cmdParser.GetBool("continue_on_error", false),
+			}
+			if envRaw, ok := v["env"].(map[string]any); ok {
+				cmd.Env = make(map[string]string, len(envRaw))
+				for k, val := range envRaw {
+					cmd.Env[k] = fmt.Sprint(val)
+				}
+			}
+			cmds = append(cmds, cmd)
+		}
+	}
+	return cmds
+}
+
+// validateCommands checks that every command has a command line, a valid
+// timeout and env templates that render.
+func validateCommands(vb *helpers.ValidationBuilder, field string, cmds []CommandConfig) {
+	for i, c := range cmds {
+		name := fmt.Sprintf("%s[%d]", field, i)
+		if c.Run == "" {
+			vb.AddError(name, fmt.Sprintf("%s: run is required", name))
+		}
+		if c.Timeout != "" && !validDuration(c.Timeout) {
+			vb.AddError(name, fmt.Sprintf("%s: invalid timeout %q", name, c.Timeout))
+		}
+		if _, err := commandEnv(c, TemplateData{}); err != nil {
+			vb.AddError(name, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+}
+
+// commandEnv returns the environment for a command: the release values as
+// RELICTA_* variables, followed by the command's rendered env.
+func commandEnv(c CommandConfig, data TemplateData) ([]string, error) {
+	env := []string{
+		"RELICTA_PACKAGE=" + data.Package,
+		"RELICTA_VERSION=" + data.Version,
+		"RELICTA_PREVIOUS_VERSION=" + data.PreviousVersion,
+		"RELICTA_TAG=" + data.Tag,
+	}
+
+	keys := make([]string, 0, len(c.Env))
+	for k := range c.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		value, err := RenderTemplate(c.Env[k], data)
+		if err != nil {
+			return nil, fmt.Errorf("env %s: %w", k, err)
+		}
+		env = append(env, k+"="+value)
+	}
+	return env, nil
+}
+
+// RunCommands runs the commands of a publish phase, such as before_publish,
+// in order through dart's shell. It stops at the first failing command
+// unless that command continues on error, and returns a warning for each
+// failure it continued past.
+func RunCommands(ctx context.Context, dart *DartCLI, phase string, cmds []CommandConfig, data TemplateData, dryRun bool, logger *slog.Logger) ([]string, error) {
+	var warnings []string
+	for _, c := range cmds {
+		env, err := commandEnv(c, data)
+		if err != nil {
+			return warnings, err
+		}
+
+		logger.Info("Running command", "phase", phase, "command", c.Run)
+		if dryRun {
+			logger.Info("[DRY-RUN] Would run command", "phase", phase, "command", c.Run, "timeout", c.timeout())
+			continue
+		}
+
+		if err := runCommand(ctx, dart, c, env); err != nil {
+			if !c.ContinueOnError {
+				return warnings, err
+			}
+			logger.Warn("Command failed", "phase", phase, "command", c.Run, "error", err)
+			warnings = append(warnings, fmt.Sprintf("warning: %s command %q failed: %v", phase, c.Run, err))
+		}
+	}
+	return warnings, nil
+}
+
+// runCommand runs one command with its env and timeout, including the tail
+// of its output in the error.
+func runCommand(ctx context.Context, dart *DartCLI, c CommandConfig, env []string) error {
+	timeout := c.timeout()
+	ctx, cancel := withTimeout(ctx, timeout, fmt.Errorf("timed out after %s", timeout))
+	defer cancel()
+
+	var output syncBuffer
+	cmdDart := *dart
+	cmdDart.SetEnv(env)
+	cmdDart.SetOutput(&output)
+
+	err := cmdDart.Shell(ctx, c.Run)
+	if err == nil {
+		return nil
+	}
+	// stderr is part of the output below
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		err = exitErr
+	}
+	err = timeoutCause(ctx, err)
+	err = fmt.Errorf("%q: %w", c.Run, err)
+	if out := tailLines(output.String(), stepOutputLines); out != "" {
+		err = fmt.Errorf("%w\n\nOutput:\n%s", err, out)
+	}
+	return err
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/helpers"
+)
+
+func TestParseCommands(t *testing.T) {
+	raw := []any{
+		"dart run build_runner build --delete-conflicting-outputs",
+		map[string]any{
+			"run":               "make assets",
+			"env":               map[string]any{"BUILD_VERSION": "{{.Version}}", "LEVEL": 3},
+			"timeout":           "2m",
+			"continue_on_error": true,
+		},
+	}
+
+	cmds := parseCommands(raw)
+	if len(cmds) != 2 {
+		t.Fatalf("expected 2 commands, got %+v", cmds)
+	}
+	if cmds[0].Run != "dart run build_runner build --delete-conflicting-outputs" || cmds[0].timeout() != DefaultCommandTimeout {
+		t.Errorf("unexpected first command: %+v", cmds[0])
+	}
+	c := cmds[1]
+	if c.Run != "make assets" || c.timeout() != 2*time.Minute || !c.ContinueOnError {
+		t.Errorf("unexpected second command: %+v", c)
+	}
+	if c.Env["BUILD_VERSION"] != "{{.Version}}" || c.Env["LEVEL"] != "3" {
+		t.Errorf("unexpected env: %v", c.Env)
+	}
+}
+
+func TestValidateCommands(t *testing.T) {
+	tests := []struct {
+		name    string
+		cmd     CommandConfig
+		wantErr string
+	}{
+		{
+			name: "valid",
+			cmd:  CommandConfig{Run: "make", Timeout: "30s", Env: map[string]string{"TAG": "{{.Tag}}"}},
+		},
+		{
+			name:    "missing run",
+			cmd:     CommandConfig{},
+			wantErr: "run is required",
+		},
+		{
+			name:    "invalid timeout",
+			cmd:     CommandConfig{Run: "make", Timeout: "soon"},
+			wantErr: `invalid timeout "soon"`,
+		},
+		{
+			name:    "invalid env template",
+			cmd:     CommandConfig{Run: "make", Env: map[string]string{"TAG": "{{.Branch}}"}},
+			wantErr: "env TAG",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vb := helpers.NewValidationBuilder()
+			validateCommands(vb, "before_publish", []CommandConfig{tt.cmd})
+			resp := vb.Build()
+
+			if tt.wantErr == "" {
+				if !resp.Valid {
+					t.Errorf("expected valid command, got %+v", resp.Errors)
+				}
+				return
+			}
+			if resp.Valid {
+				t.Fatalf("expected error containing %q", tt.wantErr)
+			}
+			if resp.Errors[0].Field != "before_publish[0]" || !strings.Contains(resp.Errors[0].Message, tt.wantErr) {
+				t.Errorf("expected error containing %q, got %+v", tt.wantErr, resp.Errors[0])
+			}
+		})
+	}
+}
+
+func TestCommandEnv(t *testing.T) {
+	env, err := commandEnv(CommandConfig{
+		Env: map[string]string{"NOTES": "{{.Package}} {{.Tag}}", "A": "1"},
+	}, TemplateData{Package: "my_pkg", Version: "1.2.0", PreviousVersion: "1.1.0", Tag: "v1.2.0"})
+	if err != nil {
+		t.Fatalf("commandEnv failed: %v", err)
+	}
+
+	want := []string{
+		"RELICTA_PACKAGE=my_pkg",
+		"RELICTA_VERSION=1.2.0",
+		"RELICTA_PREVIOUS_VERSION=1.1.0",
+		"RELICTA_TAG=v1.2.0",
+		"A=1",
+		"NOTES=my_pkg v1.2.0",
+	}
+	if strings.Join(env, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected env:\n%s", strings.Join(env, "\n"))
+	}
+}
+
+func TestRunCommands(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("commands use POSIX shell syntax")
+	}
+
+	data := TemplateData{Package: "my_pkg", Version: "1.2.0", Tag: "v1.2.0"}
+	logger := slog.New(slog.DiscardHandler)
+
+	tests := []struct {
+		name         string
+		cmds         []CommandConfig
+		dryRun       bool
+		wantErr      string
+		wantWarnings int
+		wantFile     string
+	}{
+		{
+			name: "env templating",
+			cmds: []CommandConfig{{
+				Run: `echo "$RELICTA_PACKAGE $RELICTA_VERSION $LABEL" > out.txt`,
+				Env: map[string]string{"LABEL": "release-{{.Tag}}"},
+			}},
+			wantFile: "my_pkg 1.2.0 release-v1.2.0\n",
+		},
+		{
+			name:    "failure stops",
+			cmds:    []CommandConfig{{Run: "echo generating; echo broken >&2; exit 2"}, {Run: "touch out.txt"}},
+			wantErr: "broken",
+		},
+		{
+			name: "continue on error",
+			cmds: []CommandConfig{
+				{Run: "exit 1", ContinueOnError: true},
+				{Run: "printf done > out.txt"},
+			},
+			wantWarnings: 1,
+			wantFile:     "done",
+		},
+		{
+			name:    "timeout",
+			cmds:    []CommandConfig{{Run: "sleep 5", Timeout: "100ms"}},
+			wantErr: "timed out after 100ms",
+		},
+		{
+			name:   "dry run",
+			cmds:   []CommandConfig{{Run: "touch out.txt"}},
+			dryRun: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			warnings, err := RunCommands(context.Background(), NewDartCLI(dir), "before_publish", tt.cmds, data, tt.dryRun, logger)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(warnings) != tt.wantWarnings {
+				t.Errorf("expected %d warnings, got %v", tt.wantWarnings, warnings)
+			}
+
+			data, err := os.ReadFile(filepath.Join(dir, "out.txt"))
+			if tt.wantFile == "" {
+				if err == nil {
+					t.Errorf("expected no output file, got %q", data)
+				}
+				return
+			}
+			if string(data) != tt.wantFile {
+				t.Errorf("expected output file %q, got %q (%v)", tt.wantFile, data, err)
+			}
+		})
+	}
+}
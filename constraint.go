@@ -0,0 +1,338 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	versionPattern    = regexp.MustCompile(`^(\d+)\.(\d+)\.(\d+)(?:-([0-9A-Za-z.-]+))?(?:\+([0-9A-Za-z.-]+))?$`)
+	sdkVersionPattern = regexp.MustCompile(`\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?`)
+	// A version ends at whitespace or the next operator, as in ">=2.19.0<4.0.0"
+	constraintPattern = regexp.MustCompile(`^(>=|<=|>|<|\^)?\s*([^\s<>=^]+)`)
+)
+
+// SemVer is a semantic version as used by pub.
+type SemVer struct {
+	Major      int
+	Minor      int
+	Patch      int
+	PreRelease string
+	Build      string
+}
+
+// ParseVersion parses a semantic version such as "1.2.3-dev.1+4".
+func ParseVersion(s string) (SemVer, error) {
+	m := versionPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return SemVer{}, fmt.Errorf("invalid version %q", s)
+	}
+
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	patch, _ := strconv.Atoi(m[3])
+
+	return SemVer{
+		Major:      major,
+		Minor:      minor,
+		Patch:      patch,
+		PreRelease: m[4],
+		Build:      m[5],
+	}, nil
+}
+
+// ParseSDKVersion extracts the version from `dart --version` or
+// `flutter --version` output.
+func ParseSDKVersion(output string) (SemVer, error) {
+	match := sdkVersionPattern.FindString(output)
+	if match == "" {
+		return SemVer{}, fmt.Errorf("no version found in %q", strings.TrimSpace(output))
+	}
+	return ParseVersion(match)
+}
+
+// String returns the version in its canonical form.
+func (v SemVer) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.PreRelease != "" {
+		s += "-" + v.PreRelease
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 following semver precedence. Build metadata is ignored.
+func (v SemVer) Compare(o SemVer) int {
+	for _, d := range []int{v.Major - o.Major, v.Minor - o.Minor, v.Patch - o.Patch} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+	return comparePreRelease(v.PreRelease, o.PreRelease)
+}
+
+// NextBreaking returns the next version that is incompatible under pub's
+// caret semantics: the next major, or the next minor for 0.x versions.
+func (v SemVer) NextBreaking() SemVer {
+	if v.Major == 0 {
+		return SemVer{Minor: v.Minor + 1}
+	}
+	return SemVer{Major: v.Major + 1}
+}
+
+func (v SemVer) sameRelease(o SemVer) bool {
+	return v.Major == o.Major && v.Minor == o.Minor && v.Patch == o.Patch
+}
+
+// VersionConstraint is a range of versions such as ">=2.19.0 <4.0.0".
+type VersionConstraint struct {
+	Min          *SemVer
+	MinInclusive bool
+	Max          *SemVer
+	MaxInclusive bool
+}
+
+// ParseConstraint parses pub constraint syntax: "any", an exact version,
+// a caret constraint ("^3.0.0") or a list of comparisons (">=2.19.0 <4.0.0"),
+// which pub also accepts without spaces (">=2.19.0<4.0.0").
+func ParseConstraint(s string) (VersionConstraint, error) {
+	s = strings.TrimSpace(s)
+	var c VersionConstraint
+
+	if s == "" {
+		return c, fmt.Errorf("empty version constraint")
+	}
+	if s == "any" {
+		return c, nil
+	}
+
+	rest := s
+	for rest != "" {
+		m := constraintPattern.FindStringSubmatch(rest)
+		if m == nil {
+			return c, fmt.Errorf("invalid version constraint %q", s)
+		}
+		rest = strings.TrimSpace(rest[len(m[0]):])
+
+		v, err := ParseVersion(m[2])
+		if err != nil {
+			return c, fmt.Errorf("invalid version constraint %q: %w", s, err)
+		}
+
+		switch m[1] {
+		case "^":
+			next := v.NextBreaking()
+			c.setMin(v, true)
+			c.setMax(next, false)
+		case ">=":
+			c.setMin(v, true)
+		case ">":
+			c.setMin(v, false)
+		case "<=":
+			c.setMax(v, true)
+		case "<":
+			c.setMax(v, false)
+		default:
+			c.setMin(v, true)
+			c.setMax(v, true)
+		}
+	}
+
+	return c, nil
+}
+
+// setMin narrows the lower bound.
+func (c *VersionConstraint) setMin(v SemVer, inclusive bool) {
+	if c.Min == nil || v.Compare(*c.Min) > 0 || (v.Compare(*c.Min) == 0 && !inclusive) {
+		c.Min = &v
+		c.MinInclusive = inclusive
+	}
+}
+
+// setMax narrows the upper bound.
+func (c *VersionConstraint) setMax(v SemVer, inclusive bool) {
+	if c.Max == nil || v.Compare(*c.Max) < 0 || (v.Compare(*c.Max) == 0 && !inclusive) {
+		c.Max = &v
+		c.MaxInclusive = inclusive
+	}
+}
+
+// HasUpperBound reports whether the constraint limits the maximum version.
+func (c VersionConstraint) HasUpperBound() bool {
+	return c.Max != nil
+}
+
+// IsEmpty reports whether no version can satisfy the constraint.
+func (c VersionConstraint) IsEmpty() bool {
+	if c.Min == nil || c.Max == nil {
+		return false
+	}
+	cmp := c.Min.Compare(*c.Max)
+	return cmp > 0 || (cmp == 0 && !(c.MinInclusive && c.MaxInclusive))
+}
+
+// Allows reports whether v satisfies the constraint. As in pub, an exclusive
+// upper bound on a release version also excludes its pre-releases, so
+// "<4.0.0" does not allow "4.0.0-dev.1".
+func (c VersionConstraint) Allows(v SemVer) bool {
+	if c.Min != nil {
+		cmp := v.Compare(*c.Min)
+		if cmp < 0 || (cmp == 0 && !c.MinInclusive) {
+			return false
+		}
+	}
+	if c.Max != nil {
+		cmp := v.Compare(*c.Max)
+		if cmp > 0 || (cmp == 0 && !c.MaxInclusive) {
+			return false
+		}
+		if !c.MaxInclusive && c.Max.PreRelease == "" && v.PreRelease != "" && v.sameRelease(*c.Max) {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats the constraint in pub's comparison syntax.
+func (c VersionConstraint) String() string {
+	var parts []string
+	if c.Min != nil && c.Max != nil && c.MinInclusive && c.MaxInclusive && c.Min.Compare(*c.Max) == 0 {
+		return c.Min.String()
+	}
+	if c.Min != nil {
+		op := ">"
+		if c.MinInclusive {
+			op = ">="
+		}
+		parts = append(parts, op+c.Min.String())
+	}
+	if c.Max != nil {
+		op := "<"
+		if c.MaxInclusive {
+			op = "<="
+		}
+		parts = append(parts, op+c.Max.String())
+	}
+	if len(parts) == 0 {
+		return "any"
+	}
+	return strings.Join(parts, " ")
+}
+
+// validateEnvironment checks the SDK constraints in the environment section.
+func validateEnvironment(l *issueList) {
+	env := l.pubspec.Environment
+
+	if sdk := env["sdk"]; sdk != "" {
+		c, err := ParseConstraint(sdk)
+		switch {
+		case err != nil:
+			l.errorf("environment.sdk", "sdk-constraint-invalid", "SDK constraint %v", err)
+		case c.IsEmpty():
+			l.errorf("environment.sdk", "sdk-constraint-empty", "SDK constraint %q cannot be satisfied by any version", sdk)
+		case !c.HasUpperBound():
+			l.errorf("environment.sdk", "sdk-constraint-unbounded",
+				"SDK constraint %q must have an upper bound (e.g. '>=3.0.0 <4.0.0' or '^3.0.0')", sdk)
+		}
+	}
+
+	// pub ignores upper bounds on the flutter constraint, so only validity
+	// and satisfiability are checked for it.
+	if flutter := env["flutter"]; flutter != "" {
+		c, err := ParseConstraint(flutter)
+		switch {
+		case err != nil:
+			l.errorf("environment.flutter", "flutter-constraint-invalid", "Flutter constraint %v", err)
+		case c.IsEmpty():
+			l.errorf("environment.flutter", "flutter-constraint-empty",
+				"Flutter constraint %q cannot be satisfied by any version", flutter)
+		}
+	}
+}
+
+// CheckSDKCompatibility verifies that the locally installed SDKs satisfy the
+// environment constraints. Empty version output skips the corresponding check.
+func CheckSDKCompatibility(pubspec *Pubspec, dartVersion, flutterVersion string) []ValidationIssue {
+	l := &issueList{pubspec: pubspec}
+
+	checks := []struct {
+		key    string
+		sdk    string
+		output string
+	}{
+		{"sdk", "Dart", dartVersion},
+		{"flutter", "Flutter", flutterVersion},
+	}
+	for _, check := range checks {
+		raw := pubspec.Environment[check.key]
+		if raw == "" || check.output == "" {
+			continue
+		}
+
+		c, err := ParseConstraint(raw)
+		if err != nil {
+			continue // reported by ValidatePubspec
+		}
+
+		local, err := ParseSDKVersion(check.output)
+		if err != nil {
+			l.warnf("environment."+check.key, "sdk-version-unknown",
+				"could not determine local %s SDK version: %v", check.sdk, err)
+			continue
+		}
+
+		if !c.Allows(local) {
+			l.errorf("environment."+check.key, "sdk-incompatible",
+				"local %s SDK %s does not satisfy constraint %q", check.sdk, local, raw)
+		}
+	}
+
+	return l.issues
+}
+
+func comparePreRelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		ai, aErr := strconv.Atoi(as[i])
+		bi, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if ai != bi {
+				return sign(ai - bi)
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if cmp := strings.Compare(as[i], bs[i]); cmp != 0 {
+				return cmp
+			}
+		}
+	}
+	return sign(len(as) - len(bs))
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"1.2.3", "1.2.3", false},
+		{"1.0.0+1", "1.0.0+1", false},
+		{"3.5.0-180.3.beta", "3.5.0-180.3.beta", false},
+		{"1.2", "", true},
+		{"v1.2.3", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			v, err := ParseVersion(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if v.String() != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, v.String())
+			}
+		})
+	}
+}
+
+func TestSemVer_Compare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"1.0.0", "2.0.0", -1},
+		{"1.10.0", "1.9.0", 1},
+		{"1.0.0-dev", "1.0.0", -1},
+		{"1.0.0-dev.2", "1.0.0-dev.10", -1},
+		{"1.0.0-alpha", "1.0.0-beta", -1},
+		{"1.0.0-1", "1.0.0-alpha", -1},
+		{"1.0.0+1", "1.0.0+2", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			a, _ := ParseVersion(tt.a)
+			b, _ := ParseVersion(tt.b)
+			if got := a.Compare(b); got != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseSDKVersion(t *testing.T) {
+	tests := []struct {
+		output  string
+		want    string
+		wantErr bool
+	}{
+		{`Dart SDK version: 3.5.0 (stable) (Tue Jul 30 02:17:59 2024 -0700) on "linux_x64"`, "3.5.0", false},
+		{`Dart SDK version: 3.6.0-149.0.dev (dev) on "macos_arm64"`, "3.6.0-149.0.dev", false},
+		{"Flutter 3.22.0 • channel stable • https://github.com/flutter/flutter.git", "3.22.0", false},
+		{"command not found", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			v, err := ParseSDKVersion(tt.output)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if v.String() != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, v.String())
+			}
+		})
+	}
+}
+
+func TestParseConstraint(t *testing.T) {
+	tests := []struct {
+		input      string
+		want       string
+		upperBound bool
+		empty      bool
+		wantErr    bool
+	}{
+		{"any", "any", false, false, false},
+		{"^3.0.0", ">=3.0.0 <4.0.0", true, false, false},
+		{"^0.2.3", ">=0.2.3 <0.3.0", true, false, false},
+		{"^0.0.3", ">=0.0.3 <0.1.0", true, false, false},
+		{">=2.19.0 <4.0.0", ">=2.19.0 <4.0.0", true, false, false},
+		{">=2.19.0<4.0.0", ">=2.19.0 <4.0.0", true, false, false},
+		{">= 2.19.0 < 4.0.0", ">=2.19.0 <4.0.0", true, false, false},
+		{">=2.19.0", ">=2.19.0", false, false, false},
+		{">1.0.0 <=2.0.0", ">1.0.0 <=2.0.0", true, false, false},
+		{"1.2.3", "1.2.3", true, false, false},
+		{">=4.0.0 <3.0.0", ">=4.0.0 <3.0.0", true, true, false},
+		{">=3.0.0 <3.0.0", ">=3.0.0 <3.0.0", true, true, false},
+		{"", "", false, false, true},
+		{">=3.0 <4.0", "", false, false, true},
+		{"~3.0.0", "", false, false, true},
+		{">=3.0.0=", "", false, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			c, err := ParseConstraint(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if c.String() != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, c.String())
+			}
+			if c.HasUpperBound() != tt.upperBound {
+				t.Errorf("expected upper bound %v, got %v", tt.upperBound, c.HasUpperBound())
+			}
+			if c.IsEmpty() != tt.empty {
+				t.Errorf("expected empty %v, got %v", tt.empty, c.IsEmpty())
+			}
+		})
+	}
+}
+
+func TestVersionConstraint_Allows(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{"^3.0.0", "3.5.0", true},
+		{"^3.0.0", "4.0.0", false},
+		{"^3.0.0", "2.19.6", false},
+		{">=2.19.0 <4.0.0", "3.6.0-149.0.dev", true},
+		{">=2.19.0 <4.0.0", "4.0.0-1.0.dev", false},
+		{">=3.0.0-0 <4.0.0", "3.0.0-1.0.dev", true},
+		{">1.0.0", "1.0.0", false},
+		{"<=2.0.0", "2.0.0", true},
+		{"1.2.3", "1.2.3", true},
+		{"any", "0.0.1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint+" "+tt.version, func(t *testing.T) {
+			c, err := ParseConstraint(tt.constraint)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			v, err := ParseVersion(tt.version)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := c.Allows(v); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestValidatePubspec_Environment(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		rule string
+	}{
+		{"bounded sdk", map[string]string{"sdk": ">=3.0.0 <4.0.0"}, ""},
+		{"bounded sdk without spaces", map[string]string{"sdk": ">=2.19.0<4.0.0"}, ""},
+		{"caret sdk", map[string]string{"sdk": "^3.0.0"}, ""},
+		{"unbounded sdk", map[string]string{"sdk": ">=3.0.0"}, "sdk-constraint-unbounded"},
+		{"invalid sdk", map[string]string{"sdk": "latest"}, "sdk-constraint-invalid"},
+		{"empty sdk range", map[string]string{"sdk": ">=4.0.0 <3.0.0"}, "sdk-constraint-empty"},
+		{"unbounded flutter", map[string]string{"sdk": "^3.0.0", "flutter": ">=3.10.0"}, ""},
+		{"invalid flutter", map[string]string{"sdk": "^3.0.0", "flutter": "stable"}, "flutter-constraint-invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pubspec := &Pubspec{
+				Name:        "my_package",
+				Version:     "1.0.0",
+				Description: "A package description that is long enough to pass the validation requirement of 60 chars",
+				Environment: tt.env,
+			}
+
+			issues := ValidatePubspec(pubspec)
+			if tt.rule == "" {
+				if len(issues) != 0 {
+					t.Errorf("unexpected issues: %v", issues)
+				}
+				return
+			}
+			if len(issues) != 1 || issues[0].Rule != tt.rule {
+				t.Errorf("expected single %s issue, got %v", tt.rule, issues)
+			}
+		})
+	}
+}
+
+func TestCheckSDKCompatibility(t *testing.T) {
+	pubspec := &Pubspec{
+		Environment: map[string]string{
+			"sdk":     ">=3.0.0 <4.0.0",
+			"flutter": ">=3.16.0",
+		},
+	}
+
+	tests := []struct {
+		name    string
+		dart    string
+		flutter string
+		rules   []string
+	}{
+		{"no local sdks", "", "", nil},
+		{"compatible", "Dart SDK version: 3.5.0 (stable)", "Flutter 3.22.0 • channel stable", nil},
+		{"old dart", "Dart SDK version: 2.19.6 (stable)", "", []string{"sdk-incompatible"}},
+		{"old flutter", "Dart SDK version: 3.2.0 (stable)", "Flutter 3.13.9 • channel stable", []string{"sdk-incompatible"}},
+		{"unknown dart", "garbage", "", []string{"sdk-version-unknown"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := CheckSDKCompatibility(pubspec, tt.dart, tt.flutter)
+			if len(issues) != len(tt.rules) {
+				t.Fatalf("expected %d issues, got %v", len(tt.rules), issues)
+			}
+			for i, rule := range tt.rules {
+				if issues[i].Rule != rule {
+					t.Errorf("expected rule %s, got %s", rule, issues[i].Rule)
+				}
+			}
+		})
+	}
+}
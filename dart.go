@@ -4,17 +4,30 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"runtime"
 	"strconv"
 	"strings"
+	"time"
 )
 
+// commandWaitDelay bounds how long a canceled command may keep its output
+// open, for example through a child process that outlives it.
+const commandWaitDelay = time.Second
+
 // DartCLI wraps Dart command-line operations.
 type DartCLI struct {
 	workDir     string
 	credentials *PubCredentials
 	hostedURL   string
+	// out receives the output of commands, if set.
+	out io.Writer
+	// env holds extra environment variables for commands.
+	env []string
+	// commands records the commands run, if set.
+	commands *CommandLog
 }
 
 // NewDartCLI creates a new DartCLI instance.
@@ -32,6 +45,23 @@ func (d *DartCLI) SetHostedURL(url string) {
 	d.hostedURL = url
 }
 
+// SetOutput sets a writer that receives the stdout and stderr of the
+// commands run by d.
+func (d *DartCLI) SetOutput(w io.Writer) {
+	d.out = w
+}
+
+// SetEnv sets extra environment variables, in KEY=value form, for the
+// commands run by d.
+func (d *DartCLI) SetEnv(env []string) {
+	d.env = env
+}
+
+// SetCommandLog sets a log that records every command run by d.
+func (d *DartCLI) SetCommandLog(log *CommandLog) {
+	d.commands = log
+}
+
 // Analyze runs dart analyze.
 func (d *DartCLI) Analyze(ctx context.Context) error {
 	return d.run(ctx, "dart", "analyze", "--fatal-infos", "--fatal-warnings")
@@ -52,7 +82,7 @@ func (d *DartCLI) Test(ctx context.Context, cfg TestConfig) error {
 		args = append(args, "--concurrency", strconv.Itoa(cfg.Concurrency))
 	}
 	if cfg.Coverage {
-		args = append(args, "--coverage")
+		args = append(args, "--coverage="+CoverageDir)
 	}
 
 	return d.run(ctx, "dart", args...)
@@ -75,11 +105,10 @@ func (d *DartCLI) Publish(ctx context.Context, force bool) error {
 		args = append(args, "--force")
 	}
 
-	cmd := exec.CommandContext(ctx, "dart", args...)
-	cmd.Dir = d.workDir
+	cmd := d.command(ctx, "dart", args...)
 
 	// Set credentials if available
-	env := os.Environ()
+	env := cmd.Environ()
 	if d.credentials != nil && d.credentials.AccessToken != "" {
 		env = append(env, fmt.Sprintf("PUB_TOKEN=%s", d.credentials.AccessToken))
 	}
@@ -90,8 +119,12 @@ func (d *DartCLI) Publish(ctx context.Context, force bool) error {
 
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
+	if d.out != nil {
+		cmd.Stdout = d.out
+		cmd.Stderr = io.MultiWriter(&stderr, d.out)
+	}
 
-	if err := cmd.Run(); err != nil {
+	if err := d.runLogged(cmd); err != nil {
 		errOutput := strings.TrimSpace(stderr.String())
 		if errOutput != "" {
 			return fmt.Errorf("%s: %w", errOutput, err)
@@ -103,8 +136,62 @@ func (d *DartCLI) Publish(ctx context.Context, force bool) error {
 }
 
 // GetDependencies runs pub get.
-func (d *DartCLI) GetDependencies(ctx context.Context) error {
-	return d.run(ctx, "dart", "pub", "get")
+func (d *DartCLI) GetDependencies(ctx context.Context, cfg PubGetConfig) error {
+	return d.run(ctx, "dart", pubGetArgs(cfg)...)
+}
+
+// FlutterGetDependencies runs flutter pub get.
+func (d *DartCLI) FlutterGetDependencies(ctx context.Context, cfg PubGetConfig) error {
+	return d.run(ctx, "flutter", pubGetArgs(cfg)...)
+}
+
+// pubGetArgs builds the pub get arguments shared by dart and flutter.
+func pubGetArgs(cfg PubGetConfig) []string {
+	args := []string{"pub", "get"}
+	if cfg.EnforceLockfile {
+		args = append(args, "--enforce-lockfile")
+	}
+	if cfg.Offline {
+		args = append(args, "--offline")
+	}
+	return args
+}
+
+// Downgrade resolves the lowest allowed dependency versions.
+func (d *DartCLI) Downgrade(ctx context.Context) error {
+	return d.run(ctx, "dart", "pub", "downgrade")
+}
+
+// FlutterDowngrade resolves the lowest allowed dependency versions with flutter.
+func (d *DartCLI) FlutterDowngrade(ctx context.Context) error {
+	return d.run(ctx, "flutter", "pub", "downgrade")
+}
+
+// Outdated runs pub outdated and returns the parsed report.
+func (d *DartCLI) Outdated(ctx context.Context) (*OutdatedReport, error) {
+	return d.outdated(ctx, "dart")
+}
+
+// FlutterOutdated runs flutter pub outdated and returns the parsed report.
+func (d *DartCLI) FlutterOutdated(ctx context.Context) (*OutdatedReport, error) {
+	return d.outdated(ctx, "flutter")
+}
+
+func (d *DartCLI) outdated(ctx context.Context, name string) (*OutdatedReport, error) {
+	output, err := d.output(ctx, name, "pub", "outdated", "--json", "--show-all")
+	if err != nil {
+		return nil, err
+	}
+	return ParseOutdated(output)
+}
+
+// Pana runs a locally installed pana and returns the parsed report.
+func (d *DartCLI) Pana(ctx context.Context, executable string) (*PanaReport, error) {
+	output, err := d.output(ctx, executable, "--json", "--no-warning", ".")
+	if err != nil {
+		return nil, err
+	}
+	return ParsePana(output)
 }
 
 // GetVersion returns the Dart version.
@@ -120,15 +207,60 @@ func (d *DartCLI) GetVersion(ctx context.Context) (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
-// run executes a command.
-func (d *DartCLI) run(ctx context.Context, name string, args ...string) error {
+// GetFlutterVersion returns the Flutter version.
+func (d *DartCLI) GetFlutterVersion(ctx context.Context) (string, error) {
+	output, err := d.output(ctx, "flutter", "--version")
+	if err != nil {
+		return "", fmt.Errorf("failed to get Flutter version: %w", err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Shell runs a command line through the platform shell.
+func (d *DartCLI) Shell(ctx context.Context, command string) error {
+	if runtime.GOOS == "windows" {
+		return d.run(ctx, "cmd", "/C", command)
+	}
+	return d.run(ctx, "sh", "-c", command)
+}
+
+// command prepares a command in the working directory. Canceling ctx kills
+// the command's whole process group, so tools that spawn children, such as
+// flutter test, cannot outlive it.
+func (d *DartCLI) command(ctx context.Context, name string, args ...string) *exec.Cmd {
 	cmd := exec.CommandContext(ctx, name, args...)
 	cmd.Dir = d.workDir
+	cmd.WaitDelay = commandWaitDelay
+	if len(d.env) > 0 {
+		cmd.Env = append(os.Environ(), d.env...)
+	}
+	setProcessGroup(cmd)
+	return cmd
+}
+
+// runLogged runs cmd, recording it in the command log.
+func (d *DartCLI) runLogged(cmd *exec.Cmd) error {
+	start := time.Now()
+	err := cmd.Run()
+	if d.commands != nil {
+		d.commands.add(cmd, start, err)
+	}
+	return err
+}
+
+// run executes a command.
+func (d *DartCLI) run(ctx context.Context, name string, args ...string) error {
+	cmd := d.command(ctx, name, args...)
 
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
+	if d.out != nil {
+		cmd.Stdout = d.out
+		cmd.Stderr = io.MultiWriter(&stderr, d.out)
+	}
 
-	if err := cmd.Run(); err != nil {
+	if err := d.runLogged(cmd); err != nil {
 		errOutput := strings.TrimSpace(stderr.String())
 		if errOutput != "" {
 			return fmt.Errorf("%s: %w", errOutput, err)
@@ -138,3 +270,25 @@ func (d *DartCLI) run(ctx context.Context, name string, args ...string) error {
 
 	return nil
 }
+
+// output executes a command and returns its stdout.
+func (d *DartCLI) output(ctx context.Context, name string, args ...string) ([]byte, error) {
+	cmd := d.command(ctx, name, args...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if d.out != nil {
+		cmd.Stderr = io.MultiWriter(&stderr, d.out)
+	}
+
+	if err := d.runLogged(cmd); err != nil {
+		errOutput := strings.TrimSpace(stderr.String())
+		if errOutput != "" {
+			return nil, fmt.Errorf("%s: %w", errOutput, err)
+		}
+		return nil, err
+	}
+
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPubGetArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      PubGetConfig
+		expected []string
+	}{
+		{
+			name:     "defaults",
+			cfg:      PubGetConfig{},
+			expected: []string{"pub", "get"},
+		},
+		{
+			name:     "enforce lockfile",
+			cfg:      PubGetConfig{EnforceLockfile: true},
+			expected: []string{"pub", "get", "--enforce-lockfile"},
+		},
+		{
+			name:     "offline with lockfile",
+			cfg:      PubGetConfig{EnforceLockfile: true, Offline: true},
+			expected: []string{"pub", "get", "--enforce-lockfile", "--offline"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := pubGetArgs(tt.cfg)
+			if !reflect.DeepEqual(args, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, args)
+			}
+		})
+	}
+}
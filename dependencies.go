@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// DependencySource identifies where a dependency is resolved from.
+type DependencySource string
+
+const (
+	// SourceHosted is a dependency on the default package registry.
+	SourceHosted DependencySource = "hosted"
+	// SourceCustomHosted is a dependency on a third-party registry.
+	SourceCustomHosted DependencySource = "custom-hosted"
+	// SourceSDK is a dependency provided by an SDK such as Flutter.
+	SourceSDK DependencySource = "sdk"
+	// SourcePath is a dependency on a local directory.
+	SourcePath DependencySource = "path"
+	// SourceGit is a dependency on a git repository.
+	SourceGit DependencySource = "git"
+)
+
+// Dependency is a classified pubspec dependency entry.
+type Dependency struct {
+	Name       string
+	Source     DependencySource
+	Constraint string
+	// Location is the path, git URL, hosted URL or SDK name, when applicable.
+	Location string
+}
+
+// ClassifyDependency determines the source of a dependency from its pubspec value.
+func ClassifyDependency(name string, spec any) Dependency {
+	dep := Dependency{Name: name, Source: SourceHosted}
+
+	switch v := spec.(type) {
+	case nil:
+		dep.Constraint = "any"
+	case string:
+		dep.Constraint = v
+	case map[string]any:
+		if version, ok := v["version"].(string); ok {
+			dep.Constraint = version
+		}
+
+		switch {
+		case v["sdk"] != nil:
+			dep.Source = SourceSDK
+			dep.Location = fmt.Sprint(v["sdk"])
+		case v["path"] != nil:
+			dep.Source = SourcePath
+			dep.Location = fmt.Sprint(v["path"])
+		case v["git"] != nil:
+			dep.Source = SourceGit
+			switch git := v["git"].(type) {
+			case map[string]any:
+				dep.Location = fmt.Sprint(git["url"])
+			default:
+				dep.Location = fmt.Sprint(git)
+			}
+		case v["hosted"] != nil:
+			switch hosted := v["hosted"].(type) {
+			case map[string]any:
+				// A hosted map without url uses the default registry
+				if url, ok := hosted["url"].(string); ok {
+					dep.Location = url
+				}
+			default:
+				dep.Location = fmt.Sprint(hosted)
+			}
+			if dep.Location != "" && strings.TrimRight(dep.Location, "/") != DefaultRegistryURL {
+				dep.Source = SourceCustomHosted
+			}
+		}
+	}
+
+	return dep
+}
+
+// ClassifiedDependencies returns the regular dependencies sorted by name.
+func (p *Pubspec) ClassifiedDependencies() []Dependency {
+	deps := make([]Dependency, 0, len(p.Dependencies))
+	for _, name := range slices.Sorted(maps.Keys(p.Dependencies)) {
+		deps = append(deps, ClassifyDependency(name, p.Dependencies[name]))
+	}
+	return deps
+}
+
+// validateDependencies rejects dependency sources that cannot be published.
+// Packages that declare publish_to: none are never uploaded and are skipped.
+// Hosted dependencies depend on the target registry and are checked by
+// checkDependencyHosts.
+func validateDependencies(l *issueList) {
+	pubspec := l.pubspec
+	if !pubspec.IsPublishable() {
+		return
+	}
+
+	for _, dep := range pubspec.ClassifiedDependencies() {
+		field := "dependencies." + dep.Name
+		switch dep.Source {
+		case SourcePath:
+			l.errorf(field, "dependency-path",
+				"dependency %q uses path %s; published packages cannot have path dependencies", dep.Name, dep.Location)
+		case SourceGit:
+			l.errorf(field, "dependency-git",
+				"dependency %q uses git %s; published packages cannot have git dependencies", dep.Name, dep.Location)
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(pubspec.DependencyOverrides)) {
+		l.errorf("dependency_overrides."+name, "dependency-override",
+			"dependency_overrides must be removed before publishing (overrides %q)", name)
+	}
+}
+
+// checkDependencyHosts rejects dependencies hosted on a registry other than
+// pub.dev or the one the package is published to.
+func checkDependencyHosts(pubspec *Pubspec, registry string) []ValidationIssue {
+	if !pubspec.IsPublishable() {
+		return nil
+	}
+
+	registry = strings.TrimRight(registry, "/")
+	l := &issueList{pubspec: pubspec}
+	for _, dep := range pubspec.ClassifiedDependencies() {
+		if dep.Source != SourceCustomHosted || strings.TrimRight(dep.Location, "/") == registry {
+			continue
+		}
+		if registry == DefaultRegistryURL {
+			l.errorf("dependencies."+dep.Name, "dependency-custom-hosted",
+				"dependency %q is hosted on %s; pub.dev only accepts dependencies hosted on pub.dev", dep.Name, dep.Location)
+		} else {
+			l.errorf("dependencies."+dep.Name, "dependency-custom-hosted",
+				"dependency %q is hosted on %s; packages published to %s can only depend on packages hosted there or on pub.dev",
+				dep.Name, dep.Location, registry)
+		}
+	}
+	return l.issues
+}
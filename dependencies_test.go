@@ -0,0 +1,151 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestClassifyDependency(t *testing.T) {
+	tests := []struct {
+		name       string
+		spec       any
+		source     DependencySource
+		constraint string
+		location   string
+	}{
+		{"any version", nil, SourceHosted, "any", ""},
+		{"version string", "^1.0.0", SourceHosted, "^1.0.0", ""},
+		{"version map", map[string]any{"version": "^2.0.0"}, SourceHosted, "^2.0.0", ""},
+		{"sdk", map[string]any{"sdk": "flutter"}, SourceSDK, "", "flutter"},
+		{"path", map[string]any{"path": "../core"}, SourcePath, "", "../core"},
+		{"git string", map[string]any{"git": "https://github.com/example/pkg.git"}, SourceGit, "", "https://github.com/example/pkg.git"},
+		{"git map", map[string]any{"git": map[string]any{"url": "git@github.com:example/pkg.git", "ref": "main"}}, SourceGit, "", "git@github.com:example/pkg.git"},
+		{"hosted pub.dev", map[string]any{"hosted": "https://pub.dev", "version": "^1.0.0"}, SourceHosted, "^1.0.0", "https://pub.dev"},
+		{"custom hosted", map[string]any{"hosted": "https://pub.example.com", "version": "^1.0.0"}, SourceCustomHosted, "^1.0.0", "https://pub.example.com"},
+		{"custom hosted map", map[string]any{"hosted": map[string]any{"name": "pkg", "url": "https://pub.example.com"}}, SourceCustomHosted, "", "https://pub.example.com"},
+		{"hosted map without url", map[string]any{"hosted": map[string]any{"name": "pkg"}, "version": "^2.0.0"}, SourceHosted, "^2.0.0", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep := ClassifyDependency("pkg", tt.spec)
+			if dep.Source != tt.source {
+				t.Errorf("expected source %s, got %s", tt.source, dep.Source)
+			}
+			if dep.Constraint != tt.constraint {
+				t.Errorf("expected constraint %q, got %q", tt.constraint, dep.Constraint)
+			}
+			if dep.Location != tt.location {
+				t.Errorf("expected location %q, got %q", tt.location, dep.Location)
+			}
+		})
+	}
+}
+
+func TestValidatePubspec_Dependencies(t *testing.T) {
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, "pubspec.yaml")
+	content := `name: my_package
+version: 1.0.0
+description: A package description that is long enough to pass the validation requirement of 60 chars
+environment:
+  sdk: '>=3.0.0 <4.0.0'
+dependencies:
+  flutter:
+    sdk: flutter
+  http: ^1.0.0
+  core:
+    path: ../core
+  forked:
+    git:
+      url: https://github.com/example/forked.git
+  private:
+    hosted: https://pub.example.com
+    version: ^1.0.0
+dev_dependencies:
+  test_utils:
+    path: ../test_utils
+dependency_overrides:
+  http: 1.1.0
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	pubspec, err := ParsePubspec(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	issues := ValidatePubspec(pubspec)
+
+	expected := []struct {
+		field    string
+		rule     string
+		severity Severity
+	}{
+		{"dependencies.core", "dependency-path", SeverityError},
+		{"dependencies.forked", "dependency-git", SeverityError},
+		{"dependency_overrides.http", "dependency-override", SeverityError},
+	}
+
+	if len(issues) != len(expected) {
+		t.Fatalf("expected %d issues, got %d: %v", len(expected), len(issues), issues)
+	}
+	for i, want := range expected {
+		got := issues[i]
+		if got.Field != want.field || got.Rule != want.rule || got.Severity != want.severity {
+			t.Errorf("issue %d: expected %s/%s/%s, got %s/%s/%s",
+				i, want.field, want.rule, want.severity, got.Field, got.Rule, got.Severity)
+		}
+		if got.Line == 0 {
+			t.Errorf("issue %d: expected a line number", i)
+		}
+	}
+
+	// Unpublished packages may use any dependency source
+	pubspec.PublishTo = "none"
+	if issues := ValidatePubspec(pubspec); len(issues) != 0 {
+		t.Errorf("expected no issues for publish_to: none, got %v", issues)
+	}
+}
+
+func TestCheckDependencyHosts(t *testing.T) {
+	dependencies := map[string]any{
+		"http":    "^1.0.0",
+		"private": map[string]any{"hosted": "https://pub.example.com/", "version": "^1.0.0"},
+	}
+
+	tests := []struct {
+		name      string
+		publishTo string
+		hostedURL string
+		want      string
+	}{
+		{"pub.dev by default", "", "", "pub.dev only accepts"},
+		{"explicit pub.dev", "https://pub.dev", "", "pub.dev only accepts"},
+		{"private registry through hosted_url", "", "https://pub.example.com", ""},
+		{"private registry through publish_to", "https://pub.example.com", "", ""},
+		{"another private registry", "https://pub.internal.dev", "", "packages published to https://pub.internal.dev"},
+		{"publish_to none", "none", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pubspec := &Pubspec{Name: "my_package", PublishTo: tt.publishTo, Dependencies: dependencies}
+			issues := checkDependencyHosts(pubspec, resolveRegistry(pubspec, &Config{HostedURL: tt.hostedURL}))
+			if tt.want == "" {
+				if len(issues) != 0 {
+					t.Errorf("expected no issues, got %v", issues)
+				}
+				return
+			}
+			if len(issues) != 1 || issues[0].Field != "dependencies.private" || issues[0].Rule != "dependency-custom-hosted" ||
+				issues[0].Severity != SeverityError || !strings.Contains(issues[0].Message, tt.want) {
+				t.Errorf("expected a custom-hosted error containing %q, got %v", tt.want, issues)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// scratchSkipDirs lists directories that are not copied into the scratch package.
+var scratchSkipDirs = map[string]bool{
+	".dart_tool": true,
+	".git":       true,
+	"build":      true,
+}
+
+// RunDowngradeCheck resolves the lowest allowed dependency versions in a scratch
+// copy of the package and verifies that it still analyzes (and optionally tests)
+// cleanly. The package's own pubspec.lock is left untouched.
+func RunDowngradeCheck(ctx context.Context, packageDir string, isFlutter bool, cfg DowngradeConfig, testCfg TestConfig) error {
+	scratch, err := createScratchCopy(packageDir)
+	if scratch != "" {
+		defer func() { _ = os.RemoveAll(scratch) }()
+	}
+	if err != nil {
+		return err
+	}
+
+	dart := NewDartCLI(scratch)
+
+	if isFlutter {
+		err = dart.FlutterDowngrade(ctx)
+	} else {
+		err = dart.Downgrade(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("pub downgrade failed: %w", err)
+	}
+
+	if err := dart.Analyze(ctx); err != nil {
+		return fmt.Errorf("analysis failed with downgraded dependencies: %w", err)
+	}
+
+	if cfg.Test {
+		if isFlutter {
+			err = dart.FlutterTest(ctx)
+		} else {
+			err = dart.Test(ctx, testCfg)
+		}
+		if err != nil {
+			return fmt.Errorf("tests failed with downgraded dependencies: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// createScratchCopy copies the package into a temporary directory. Relative
+// path dependencies such as ../shared are made absolute in the copy so they
+// resolve to the same packages. The caller removes the returned directory,
+// which is set even if copying fails.
+func createScratchCopy(packageDir string) (string, error) {
+	absDir, err := filepath.Abs(packageDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve package directory: %w", err)
+	}
+
+	scratch, err := os.MkdirTemp("", "pub-downgrade-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create scratch directory: %w", err)
+	}
+	if err := CopyPackage(absDir, scratch); err != nil {
+		return scratch, err
+	}
+	for _, name := range []string{"pubspec.yaml", "pubspec_overrides.yaml"} {
+		if err := absolutizePathDependencies(filepath.Join(scratch, name), absDir); err != nil {
+			return scratch, err
+		}
+	}
+	return scratch, nil
+}
+
+// absolutizePathDependencies rewrites the relative path dependencies of a
+// pubspec file to absolute paths under base. Missing files are ignored.
+func absolutizePathDependencies(path, base string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+	}
+	if len(node.Content) == 0 || node.Content[0].Kind != yaml.MappingNode {
+		return nil
+	}
+
+	changed := false
+	root := node.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		switch root.Content[i].Value {
+		case "dependencies", "dev_dependencies", "dependency_overrides":
+			if absolutizePaths(root.Content[i+1], base) {
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	out, err := yaml.Marshal(&node)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", filepath.Base(path), err)
+	}
+	return os.WriteFile(path, out, 0644)
+}
+
+// absolutizePaths rewrites the relative path entries of a dependencies
+// mapping and reports whether any changed.
+func absolutizePaths(deps *yaml.Node, base string) bool {
+	if deps.Kind != yaml.MappingNode {
+		return false
+	}
+
+	changed := false
+	for i := 1; i < len(deps.Content); i += 2 {
+		spec := deps.Content[i]
+		if spec.Kind != yaml.MappingNode {
+			continue
+		}
+		for j := 0; j+1 < len(spec.Content); j += 2 {
+			value := spec.Content[j+1]
+			if spec.Content[j].Value != "path" || value.Kind != yaml.ScalarNode || filepath.IsAbs(filepath.FromSlash(value.Value)) {
+				continue
+			}
+			value.Value = filepath.ToSlash(filepath.Join(base, filepath.FromSlash(value.Value)))
+			value.Style = yaml.DoubleQuotedStyle
+			changed = true
+		}
+	}
+	return changed
+}
+
+// CopyPackage copies a package directory to dst, skipping tool and build output.
+func CopyPackage(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		if entry.IsDir() {
+			if rel != "." && scratchSkipDirs[entry.Name()] {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(target, 0755)
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		return copyFile(path, target)
+	})
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", src, err)
+	}
+	defer func() { _ = in.Close() }()
+
+	info, err := in.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", src, err)
+	}
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", dst, err)
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("failed to copy %s: %w", src, err)
+	}
+
+	return out.Close()
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCopyPackage(t *testing.T) {
+	src := t.TempDir()
+	files := map[string]string{
+		"pubspec.yaml":                   "name: my_package\n",
+		"lib/my_package.dart":            "void main() {}\n",
+		"test/my_package_test.dart":      "void main() {}\n",
+		".dart_tool/package_config.json": "{}",
+		"build/output.txt":               "artefact",
+		".git/HEAD":                      "ref: refs/heads/main\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(src, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+
+	dst := t.TempDir()
+	if err := CopyPackage(src, dst); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, name := range []string{"pubspec.yaml", "lib/my_package.dart", "test/my_package_test.dart"} {
+		data, err := os.ReadFile(filepath.Join(dst, name))
+		if err != nil {
+			t.Errorf("expected %s to be copied: %v", name, err)
+			continue
+		}
+		if string(data) != files[name] {
+			t.Errorf("expected %s content %q, got %q", name, files[name], string(data))
+		}
+	}
+
+	for _, dir := range []string{".dart_tool", "build", ".git"} {
+		if _, err := os.Stat(filepath.Join(dst, dir)); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be skipped", dir)
+		}
+	}
+}
+
+func TestCreateScratchCopy_RelativePathDependency(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"my_package/pubspec.yaml":           "name: my_package\ndev_dependencies:\n  shared:\n    path: ../shared\n",
+		"my_package/pubspec_overrides.yaml": "dependency_overrides:\n  shared:\n    path: ../shared\n",
+		"shared/pubspec.yaml":               "name: shared\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+
+	scratch, err := createScratchCopy(filepath.Join(root, "my_package"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(scratch) }()
+
+	pubspec, err := ParsePubspec(filepath.Join(scratch, "pubspec.yaml"))
+	if err != nil {
+		t.Fatalf("expected the pubspec to be copied: %v", err)
+	}
+	dep := ClassifyDependency("shared", pubspec.DevDeps["shared"])
+	if dep.Source != SourcePath {
+		t.Fatalf("expected a path dependency, got %+v", dep)
+	}
+	if filepath.Dir(scratch) == root {
+		t.Errorf("expected the scratch copy outside the package's parent directory, got %s", scratch)
+	}
+	if want := filepath.ToSlash(filepath.Join(root, "shared")); dep.Location != want {
+		t.Errorf("expected the path dependency to point at %s, got %s", want, dep.Location)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.FromSlash(dep.Location), "pubspec.yaml")); err != nil {
+		t.Errorf("expected %s to resolve from the scratch copy: %v", dep.Location, err)
+	}
+
+	overrides, err := os.ReadFile(filepath.Join(scratch, "pubspec_overrides.yaml"))
+	if err != nil || !strings.Contains(string(overrides), `path: "`+dep.Location+`"`) {
+		t.Errorf("expected the override path to be made absolute, got %q, %v", overrides, err)
+	}
+}
+
+func TestRunDowngradeCheck_LeavesLockfile(t *testing.T) {
+	t.Setenv("PATH", "")
+
+	dir := t.TempDir()
+	lock := "packages: {}\n"
+	if err := os.WriteFile(filepath.Join(dir, "pubspec.yaml"), []byte("name: my_package\n"), 0644); err != nil {
+		t.Fatalf("failed to write pubspec: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "pubspec.lock"), []byte(lock), 0644); err != nil {
+		t.Fatalf("failed to write lockfile: %v", err)
+	}
+
+	err := RunDowngradeCheck(context.Background(), dir, false, DowngradeConfig{}, TestConfig{})
+	if err == nil {
+		t.Fatal("expected error without dart in PATH")
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "pubspec.lock"))
+	if err != nil {
+		t.Fatalf("failed to read lockfile: %v", err)
+	}
+	if string(data) != lock {
+		t.Errorf("expected lockfile to be unchanged, got %q", string(data))
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// GitCLI wraps git command-line operations.
+type GitCLI struct {
+	workDir     string
+	authorName  string
+	authorEmail string
+}
+
+// NewGitCLI creates a new GitCLI instance.
+func NewGitCLI(workDir string) *GitCLI {
+	return &GitCLI{workDir: workDir}
+}
+
+// SetIdentity sets the author and committer identity used for commits.
+// Empty values fall back to the repository configuration.
+func (g *GitCLI) SetIdentity(name, email string) {
+	g.authorName = name
+	g.authorEmail = email
+}
+
+// Prefix returns the path of the working directory relative to the
+// repository root, with a trailing slash, or "" at the root.
+func (g *GitCLI) Prefix(ctx context.Context) (string, error) {
+	out, err := g.output(ctx, "rev-parse", "--show-prefix")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Head returns the full SHA of the HEAD commit.
+func (g *GitCLI) Head(ctx context.Context) (string, error) {
+	return g.resolve(ctx, "HEAD")
+}
+
+// TagCommit returns the commit a tag points at, or "" if the tag does not exist.
+func (g *GitCLI) TagCommit(ctx context.Context, tag string) (string, error) {
+	if _, err := g.output(ctx, "show-ref", "--verify", "--quiet", "refs/tags/"+tag); err != nil {
+		return "", nil
+	}
+	return g.resolve(ctx, "refs/tags/"+tag+"^{commit}")
+}
+
+// RemoteURL returns the fetch URL of the named remote.
+func (g *GitCLI) RemoteURL(ctx context.Context, remote string) (string, error) {
+	out, err := g.output(ctx, "remote", "get-url", remote)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Status returns the paths, relative to the repository root, of changed and
+// untracked files under the working directory.
+func (g *GitCLI) Status(ctx context.Context) ([]string, error) {
+	out, err := g.output(ctx, "status", "--porcelain=v1", "-z", "--untracked-files=all", "--", ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	entries := strings.Split(string(out), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		paths = append(paths, entry[3:])
+		// Renames and copies are followed by the original path
+		if entry[0] == 'R' || entry[0] == 'C' {
+			i++
+		}
+	}
+
+	return paths, nil
+}
+
+// Commit stages the given paths and commits them, returning the new HEAD.
+func (g *GitCLI) Commit(ctx context.Context, message string, paths ...string) (string, error) {
+	if _, err := g.output(ctx, append([]string{"add", "--"}, paths...)...); err != nil {
+		return "", err
+	}
+	args := append([]string{"commit", "--quiet", "-m", message, "--"}, paths...)
+	if _, err := g.output(ctx, args...); err != nil {
+		return "", err
+	}
+	return g.Head(ctx)
+}
+
+// Reset moves HEAD and the index to rev, keeping the working tree.
+func (g *GitCLI) Reset(ctx context.Context, rev string) error {
+	_, err := g.output(ctx, "reset", "--quiet", "--mixed", rev)
+	return err
+}
+
+func (g *GitCLI) resolve(ctx context.Context, rev string) (string, error) {
+	out, err := g.output(ctx, "rev-parse", "--verify", rev)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// output executes a git command and returns its stdout.
+func (g *GitCLI) output(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = g.workDir
+	cmd.Env = os.Environ()
+	if g.authorName != "" {
+		cmd.Env = append(cmd.Env, "GIT_AUTHOR_NAME="+g.authorName, "GIT_COMMITTER_NAME="+g.authorName)
+	}
+	if g.authorEmail != "" {
+		cmd.Env = append(cmd.Env, "GIT_AUTHOR_EMAIL="+g.authorEmail, "GIT_COMMITTER_EMAIL="+g.authorEmail)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		errOutput := strings.TrimSpace(stderr.String())
+		if errOutput != "" {
+			return nil, fmt.Errorf("git %s: %s: %w", args[0], errOutput, err)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// GitCheck describes the expected repository state for a release.
+type GitCheck struct {
+	Config GitConfig
+	// Modified lists package-relative files the plugin is allowed to leave
+	// changed, such as pubspec.yaml after a version bump.
+	Modified   []string
+	CommitSHA  string
+	TagName    string
+	Repository string
+	// ReleaseCommit is a commit the plugin made on top of CommitSHA.
+	ReleaseCommit string
+}
+
+// CheckGitState verifies the working tree, HEAD and remote against the
+// release and returns every mismatch found.
+func CheckGitState(ctx context.Context, git *GitCLI, check GitCheck) ([]string, error) {
+	var problems []string
+
+	if check.Config.Clean {
+		prefix, err := git.Prefix(ctx)
+		if err != nil {
+			return nil, err
+		}
+		changed, err := git.Status(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		allowed := make(map[string]bool, len(check.Modified))
+		for _, f := range check.Modified {
+			allowed[prefix+f] = true
+		}
+		var dirty []string
+		for _, f := range changed {
+			if !allowed[f] && !strings.HasPrefix(f, prefix+StateDir+"/") {
+				dirty = append(dirty, f)
+			}
+		}
+		if len(dirty) > 0 {
+			problems = append(problems, fmt.Sprintf("working tree has uncommitted changes: %s", strings.Join(dirty, ", ")))
+		}
+	}
+
+	if check.Config.Commit && (check.CommitSHA != "" || check.TagName != "") {
+		head, err := git.Head(ctx)
+		if err != nil {
+			return nil, err
+		}
+		base := head
+		if check.ReleaseCommit != "" && head == check.ReleaseCommit {
+			if base, err = git.resolve(ctx, head+"~1"); err != nil {
+				return nil, err
+			}
+		}
+		if check.CommitSHA != "" && !sameCommit(base, check.CommitSHA) {
+			problems = append(problems, fmt.Sprintf("HEAD is %s but the release is for commit %s", shortSHA(head), check.CommitSHA))
+		}
+		if check.TagName != "" {
+			tagged, err := git.TagCommit(ctx, check.TagName)
+			if err != nil {
+				return nil, err
+			}
+			if tagged != "" && tagged != head && tagged != base {
+				problems = append(problems, fmt.Sprintf("tag %s points at %s but HEAD is %s", check.TagName, shortSHA(tagged), shortSHA(head)))
+			}
+		}
+	}
+
+	if check.Config.Repository && check.Repository != "" {
+		remote, err := git.RemoteURL(ctx, check.Config.Remote)
+		if err != nil {
+			return nil, err
+		}
+		if !repositoryMatches(check.Repository, remote) {
+			problems = append(problems, fmt.Sprintf("pubspec repository %s does not match remote %s (%s)", check.Repository, check.Config.Remote, remote))
+		}
+	}
+
+	return problems, nil
+}
+
+// CommitReleaseFiles commits those of the given package-relative files that
+// have changes. It returns the new commit SHA, or "" if nothing changed.
+func CommitReleaseFiles(ctx context.Context, git *GitCLI, files []string, message string) (string, error) {
+	prefix, err := git.Prefix(ctx)
+	if err != nil {
+		return "", err
+	}
+	changed, err := git.Status(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	isChanged := make(map[string]bool, len(changed))
+	for _, f := range changed {
+		isChanged[f] = true
+	}
+	var paths []string
+	for _, f := range files {
+		if isChanged[prefix+f] {
+			paths = append(paths, f)
+		}
+	}
+	if len(paths) == 0 {
+		return "", nil
+	}
+
+	return git.Commit(ctx, message, paths...)
+}
+
+// sameCommit compares a full SHA against a possibly abbreviated one.
+func sameCommit(full, sha string) bool {
+	sha = strings.ToLower(sha)
+	return len(sha) >= 7 && strings.HasPrefix(full, sha)
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
+}
+
+// repositoryMatches reports whether a pubspec repository URL refers to the
+// git remote. The repository may point into the remote, as monorepo
+// packages do with URLs like https://github.com/org/repo/tree/main/pkg.
+func repositoryMatches(repository, remote string) bool {
+	repo := normalizeRepoURL(repository)
+	origin := normalizeRepoURL(remote)
+	if repo == "" || origin == "" {
+		return false
+	}
+	return repo == origin || strings.HasPrefix(repo, origin+"/")
+}
+
+// normalizeRepoURL reduces http, ssh and scp-style git URLs to host/path.
+func normalizeRepoURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+
+	var host, p string
+	if u, err := url.Parse(raw); err == nil && u.Host != "" {
+		host, p = u.Hostname(), u.Path
+	} else if at := strings.Index(raw, "@"); at >= 0 && strings.Contains(raw[at:], ":") {
+		// scp-like syntax: git@github.com:org/repo.git
+		rest := raw[at+1:]
+		colon := strings.Index(rest, ":")
+		host, p = rest[:colon], rest[colon+1:]
+	} else {
+		return ""
+	}
+
+	p = strings.TrimSuffix(path.Clean("/"+p), "/")
+	p = strings.TrimSuffix(p, ".git")
+	return strings.ToLower(host + p)
+}
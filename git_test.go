@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// initGitRepo creates a repository with one commit containing files and
+// returns the HEAD SHA.
+func initGitRepo(t *testing.T, dir string, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	writePackageFiles(t, dir, files)
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.name", "Test"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "commit.gpgsign", "false"},
+		{"add", "-A"},
+		{"commit", "-q", "-m", "initial"},
+	} {
+		gitCmd(t, dir, args...)
+	}
+	return strings.TrimSpace(gitCmd(t, dir, "rev-parse", "HEAD"))
+}
+
+func gitCmd(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return string(out)
+}
+
+func TestCheckGitState(t *testing.T) {
+	allChecks := GitConfig{Remote: "origin", Clean: true, Commit: true, Repository: true}
+
+	tests := []struct {
+		name    string
+		setup   func(t *testing.T, root, head string) GitCheck
+		wantErr bool
+		want    []string
+	}{
+		{
+			name: "consistent",
+			setup: func(t *testing.T, root, head string) GitCheck {
+				gitCmd(t, root, "tag", "v1.0.0")
+				return GitCheck{
+					Config:     allChecks,
+					CommitSHA:  head[:7],
+					TagName:    "v1.0.0",
+					Repository: "https://github.com/acme/tools/tree/main/pkg",
+				}
+			},
+		},
+		{
+			name: "plugin modified files are allowed",
+			setup: func(t *testing.T, root, head string) GitCheck {
+				writePackageFiles(t, filepath.Join(root, "pkg"), map[string]string{
+					"pubspec.yaml": "name: pkg\nversion: 1.1.0\n",
+					"CHANGELOG.md": "## 1.1.0\n",
+				})
+				return GitCheck{Config: allChecks, Modified: []string{"pubspec.yaml", "CHANGELOG.md"}}
+			},
+		},
+		{
+			name: "dirty tree",
+			setup: func(t *testing.T, root, head string) GitCheck {
+				writePackageFiles(t, filepath.Join(root, "pkg"), map[string]string{
+					"pubspec.yaml":    "name: pkg\nversion: 1.1.0\n",
+					"lib/pkg.dart":    "// changed\n",
+					"lib/new.dart":    "// untracked\n",
+					"../other/x.dart": "// outside the package\n",
+				})
+				return GitCheck{Config: allChecks, Modified: []string{"pubspec.yaml"}}
+			},
+			want: []string{"uncommitted changes: pkg/lib/pkg.dart, pkg/lib/new.dart"},
+		},
+		{
+			name: "HEAD and tag mismatch",
+			setup: func(t *testing.T, root, head string) GitCheck {
+				gitCmd(t, root, "tag", "v1.0.0")
+				gitCmd(t, root, "commit", "-q", "--allow-empty", "-m", "next")
+				return GitCheck{Config: allChecks, CommitSHA: head, TagName: "v1.0.0"}
+			},
+			want: []string{
+				"but the release is for commit",
+				"tag v1.0.0 points at",
+			},
+		},
+		{
+			name: "release commit on top of the release",
+			setup: func(t *testing.T, root, head string) GitCheck {
+				gitCmd(t, root, "tag", "v1.0.0")
+				gitCmd(t, root, "commit", "-q", "--allow-empty", "-m", "chore(release): pkg 1.0.0")
+				release := strings.TrimSpace(gitCmd(t, root, "rev-parse", "HEAD"))
+				return GitCheck{Config: allChecks, CommitSHA: head, TagName: "v1.0.0", ReleaseCommit: release}
+			},
+		},
+		{
+			name: "repository mismatch",
+			setup: func(t *testing.T, root, head string) GitCheck {
+				return GitCheck{Config: allChecks, Repository: "https://github.com/acme/other"}
+			},
+			want: []string{"pubspec repository https://github.com/acme/other does not match remote origin"},
+		},
+		{
+			name: "disabled checks",
+			setup: func(t *testing.T, root, head string) GitCheck {
+				writePackageFiles(t, filepath.Join(root, "pkg"), map[string]string{"lib/pkg.dart": "// changed\n"})
+				return GitCheck{Config: GitConfig{Remote: "origin"}, CommitSHA: "0000000", Repository: "https://example.com/x"}
+			},
+		},
+		{
+			name: "missing remote",
+			setup: func(t *testing.T, root, head string) GitCheck {
+				return GitCheck{Config: GitConfig{Remote: "upstream", Repository: true}, Repository: "https://github.com/acme/tools"}
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			head := initGitRepo(t, root, map[string]string{
+				"pkg/pubspec.yaml": "name: pkg\nversion: 1.0.0\n",
+				"pkg/lib/pkg.dart": "// pkg\n",
+			})
+			gitCmd(t, root, "remote", "add", "origin", "git@github.com:acme/tools.git")
+
+			check := tt.setup(t, root, head)
+			problems, err := CheckGitState(context.Background(), NewGitCLI(filepath.Join(root, "pkg")), check)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckGitState() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			got := strings.Join(problems, "\n")
+			if len(tt.want) == 0 && got != "" {
+				t.Errorf("unexpected problems: %s", got)
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("problems %q do not contain %q", got, w)
+				}
+			}
+		})
+	}
+}
+
+func TestRepositoryMatches(t *testing.T) {
+	tests := []struct {
+		repository string
+		remote     string
+		want       bool
+	}{
+		{"https://github.com/acme/tools", "https://github.com/acme/tools.git", true},
+		{"https://github.com/Acme/Tools", "git@github.com:acme/tools.git", true},
+		{"https://github.com/acme/tools/tree/main/packages/pkg", "ssh://git@github.com/acme/tools.git", true},
+		{"https://github.com/acme/tools/", "https://user@github.com/acme/tools", true},
+		{"https://github.com/acme/tools-extra", "https://github.com/acme/tools.git", false},
+		{"https://gitlab.com/acme/tools", "https://github.com/acme/tools.git", false},
+		{"https://github.com/acme/tools", "/srv/git/tools.git", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.repository+" "+tt.remote, func(t *testing.T) {
+			if got := repositoryMatches(tt.repository, tt.remote); got != tt.want {
+				t.Errorf("repositoryMatches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommitReleaseFiles(t *testing.T) {
+	root := t.TempDir()
+	initGitRepo(t, root, map[string]string{
+		"pkg/pubspec.yaml": "name: pkg\nversion: 1.0.0\n",
+		"pkg/CHANGELOG.md": "## 1.0.0\n",
+		"pkg/lib/pkg.dart": "// pkg\n",
+	})
+	git := NewGitCLI(filepath.Join(root, "pkg"))
+	files := []string{"pubspec.yaml", "pubspec.lock", "CHANGELOG.md"}
+	ctx := context.Background()
+
+	sha, err := CommitReleaseFiles(ctx, git, files, "unused")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sha != "" {
+		t.Errorf("expected no commit for a clean tree, got %s", sha)
+	}
+
+	writePackageFiles(t, filepath.Join(root, "pkg"), map[string]string{
+		"pubspec.yaml": "name: pkg\nversion: 1.1.0\n",
+		"pubspec.lock": "packages: {}\n",
+		"lib/pkg.dart": "// unrelated change\n",
+	})
+	git.SetIdentity("Release Bot", "release@example.com")
+
+	sha, err = CommitReleaseFiles(ctx, git, files, "chore(release): pkg 1.1.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if head := strings.TrimSpace(gitCmd(t, root, "rev-parse", "HEAD")); sha != head {
+		t.Errorf("expected returned SHA %s to be HEAD %s", sha, head)
+	}
+
+	show := gitCmd(t, root, "show", "--name-only", "--format=%an <%ae>|%cn|%s", "HEAD")
+	for _, want := range []string{
+		"Release Bot <release@example.com>|Release Bot|chore(release): pkg 1.1.0",
+		"pkg/pubspec.yaml",
+		"pkg/pubspec.lock",
+	} {
+		if !strings.Contains(show, want) {
+			t.Errorf("commit %q does not contain %q", show, want)
+		}
+	}
+	if strings.Contains(show, "lib/pkg.dart") {
+		t.Errorf("commit includes unrelated file: %s", show)
+	}
+	if status := gitCmd(t, root, "status", "--porcelain"); !strings.Contains(status, "pkg/lib/pkg.dart") {
+		t.Errorf("expected unrelated change to remain uncommitted, status: %q", status)
+	}
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/helpers"
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// StepHooksConfig assigns the steps that change release files to a hook.
+// Each value is one of "post-version", "post-notes" or "pre-publish".
+type StepHooksConfig struct {
+	UpdateVersion string `json:"update_version"`
+	Changelog     string `json:"changelog"`
+	GitCommit     string `json:"git_commit"`
+}
+
+// stepHookOrder lists the hooks file-changing steps may run in, in the
+// order Relicta runs them.
+var stepHookOrder = []plugin.Hook{
+	plugin.HookPostVersion,
+	plugin.HookPostNotes,
+	plugin.HookPrePublish,
+}
+
+// validateStepHooks checks that every step is assigned to a supported hook,
+// that the commit does not run before the files it commits change, and that
+// the changelog is not generated before the release notes exist.
+func validateStepHooks(vb *helpers.ValidationBuilder, cfg *Config) {
+	hooks := cfg.StepHooks
+	fields := []struct {
+		field string
+		value string
+	}{
+		{"step_hooks.update_version", hooks.UpdateVersion},
+		{"step_hooks.changelog", hooks.Changelog},
+		{"step_hooks.git_commit", hooks.GitCommit},
+	}
+	valid := true
+	for _, f := range fields {
+		if hookIndex(f.value) < 0 {
+			vb.AddError(f.field, fmt.Sprintf("%s must be one of: post-version, post-notes, pre-publish", f.field))
+			valid = false
+		}
+	}
+
+	commit := hookIndex(hooks.GitCommit)
+	if valid && (commit < hookIndex(hooks.UpdateVersion) || commit < hookIndex(hooks.Changelog)) {
+		vb.AddError("step_hooks.git_commit", "step_hooks.git_commit must not run before update_version and changelog")
+	}
+	if cfg.Changelog && cfg.ChangelogConfig.Generate && hooks.Changelog == string(plugin.HookPostVersion) {
+		vb.AddError("step_hooks.changelog", "step_hooks.changelog must not be post-version with changelog_config.generate: release notes do not exist yet")
+	}
+}
+
+func hookIndex(hook string) int {
+	for i, h := range stepHookOrder {
+		if string(h) == hook {
+			return i
+		}
+	}
+	return -1
+}
+
+// releaseRun holds the state shared by the steps of one hook execution.
+type releaseRun struct {
+	cfg         *Config
+	release     *plugin.ReleaseContext
+	logger      *slog.Logger
+	pubspecPath string
+	packageDir  string
+	pubspec     *Pubspec
+	isFlutter   bool
+	dart        *DartCLI
+	snapshot    *Snapshot
+	// files caches the package file list shared by the archive and secret steps.
+	files *packageFileList
+	// notes collects the summaries of the step being run.
+	notes []string
+}
+
+// newReleaseRun parses the pubspec and opens the rollback snapshot for the
+// release.
+func newReleaseRun(releaseCtx *plugin.ReleaseContext, cfg *Config, logger *slog.Logger) (*releaseRun, error) {
+	pubspecPath := cfg.PubspecPath
+	if pubspecPath == "" {
+		pubspecPath = "pubspec.yaml"
+	}
+
+	pubspec, err := ParsePubspec(pubspecPath)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &releaseRun{
+		cfg:         cfg,
+		release:     releaseCtx,
+		logger:      logger.With("version", releaseCtx.Version, "package", pubspec.Name),
+		pubspecPath: pubspecPath,
+		packageDir:  filepath.Dir(pubspecPath),
+		pubspec:     pubspec,
+		isFlutter:   IsFlutterPackage(pubspec),
+		dart:        NewDartCLI(filepath.Dir(pubspecPath)),
+		files:       &packageFileList{},
+	}
+	if r.isFlutter {
+		r.logger = r.logger.With("flutter", true)
+	}
+
+	// Snapshot files before changing them so a failed release can be rolled back
+	if !cfg.DryRun {
+		r.snapshot, err = OpenSnapshot(r.packageDir, releaseCtx.Version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to prepare rollback: %w", err)
+		}
+	}
+
+	return r, nil
+}
+
+// updateVersion writes the release version to pubspec.yaml.
+func (r *releaseRun) updateVersion(_ context.Context) error {
+	r.logger.Info("Updating version in pubspec.yaml")
+	if r.cfg.DryRun {
+		r.logger.Info("[DRY-RUN] Would update version", "from", r.pubspec.Version, "to", r.release.Version)
+		return nil
+	}
+
+	if err := r.snapshot.Capture(filepath.Base(r.pubspecPath)); err != nil {
+		return fmt.Errorf("failed to prepare rollback: %w", err)
+	}
+	return UpdateVersion(r.pubspecPath, r.release.Version)
+}
+
+// changelog verifies CHANGELOG.md has an entry for the release, generating
+// one from the release notes if configured.
+func (r *releaseRun) changelog(_ context.Context) error {
+	version := r.release.Version
+	changelogPath := filepath.Join(r.packageDir, ChangelogFile)
+	r.logger.Info("Checking CHANGELOG.md entry")
+	hasEntry, err := ChangelogHasVersion(changelogPath, version)
+	if err != nil {
+		return err
+	}
+	if hasEntry {
+		return nil
+	}
+	if !r.cfg.ChangelogConfig.Generate {
+		return fmt.Errorf("%s has no entry for %s", ChangelogFile, version)
+	}
+
+	notes := r.release.ReleaseNotes
+	if notes == "" {
+		notes = r.release.Changelog
+	}
+	if strings.TrimSpace(notes) == "" {
+		return fmt.Errorf("%s has no entry for %s and there are no release notes to generate it from", ChangelogFile, version)
+	}
+	if r.cfg.DryRun {
+		r.logger.Info("[DRY-RUN] Would add CHANGELOG.md entry", "version", version)
+		return nil
+	}
+
+	if err := r.snapshot.Capture(ChangelogFile); err != nil {
+		return fmt.Errorf("failed to prepare rollback: %w", err)
+	}
+	if err := PrependChangelogEntry(changelogPath, version, notes); err != nil {
+		return fmt.Errorf("failed to update changelog: %w", err)
+	}
+	return nil
+}
+
+// gitCommit commits the files the plugin changed.
+func (r *releaseRun) gitCommit(ctx context.Context) error {
+	message, err := RenderTemplate(r.cfg.GitCommitConfig.Message, TemplateData{
+		Package:         r.pubspec.Name,
+		Version:         r.release.Version,
+		PreviousVersion: r.release.PreviousVersion,
+		Tag:             r.release.TagName,
+	})
+	if err != nil {
+		return err
+	}
+
+	files := pluginModifiedFiles(r.pubspecPath, r.cfg)
+	r.logger.Info("Committing release files", "files", files)
+	if r.cfg.DryRun {
+		r.logger.Info("[DRY-RUN] Would commit release files", "files", files, "message", message)
+		return nil
+	}
+
+	git := NewGitCLI(r.packageDir)
+	git.SetIdentity(r.cfg.GitCommitConfig.AuthorName, r.cfg.GitCommitConfig.AuthorEmail)
+	sha, err := CommitReleaseFiles(ctx, git, files, message)
+	if err != nil {
+		return err
+	}
+	if sha == "" {
+		r.logger.Info("No release file changes to commit")
+		return nil
+	}
+	if err := r.snapshot.RecordCommit(sha); err != nil {
+		return fmt.Errorf("failed to record release commit: %w", err)
+	}
+	r.notes = append(r.notes, fmt.Sprintf("Committed release files as %s", shortSHA(sha)))
+	return nil
+}
+
+// executeFileSteps runs the file-changing steps assigned to an early hook
+// such as PostVersion, so the bump can be reviewed before publishing.
+func (p *PubPlugin) executeFileSteps(ctx context.Context, hook plugin.Hook, releaseCtx *plugin.ReleaseContext, cfg *Config, logger *slog.Logger) (*plugin.ExecuteResponse, error) {
+	r, err := newReleaseRun(releaseCtx, cfg, logger)
+	if err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to prepare release: %v", err),
+		}, nil
+	}
+
+	results, fatal := r.runPipeline(ctx, hook)
+	return r.pipelineResponse(hook, results, fatal), nil
+}
+
+// executeOnSuccess summarizes the release and clears the state kept for
+// rollback and retraction.
+func (p *PubPlugin) executeOnSuccess(releaseCtx *plugin.ReleaseContext, cfg *Config, logger *slog.Logger) (*plugin.ExecuteResponse, error) {
+	pubspecPath := cfg.PubspecPath
+	if pubspecPath == "" {
+		pubspecPath = "pubspec.yaml"
+	}
+	packageDir := filepath.Dir(pubspecPath)
+
+	if !cfg.DryRun {
+		snapshot, err := LoadSnapshot(packageDir)
+		if err == nil {
+			err = snapshot.Discard()
+		}
+		if err != nil {
+			logger.Warn("Failed to discard rollback snapshot", "error", err)
+		}
+		if err := RemovePublishRecord(packageDir); err != nil {
+			logger.Warn("Failed to remove publish record", "error", err)
+		}
+	}
+
+	name := "package"
+	if pubspec, err := ParsePubspec(pubspecPath); err == nil {
+		name = pubspec.Name
+	}
+
+	return &plugin.ExecuteResponse{
+		Success: true,
+		Message: fmt.Sprintf("Release of %s@%s completed", name, releaseCtx.Version),
+	}, nil
+}
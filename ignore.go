@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ignorePattern is a single .gitignore-style rule.
+type ignorePattern struct {
+	// base is the slash-separated directory the rule is relative to ("" for the root).
+	base    string
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// parseIgnorePattern parses one line of a .gitignore or .pubignore file.
+func parseIgnorePattern(base, line string) (ignorePattern, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignorePattern{}, false
+	}
+
+	p := ignorePattern{base: base}
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return ignorePattern{}, false
+	}
+
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	expr := globToRegexp(line)
+	if anchored {
+		expr = "^" + expr + "$"
+	} else {
+		expr = "^(?:.*/)?" + expr + "$"
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return ignorePattern{}, false
+	}
+	p.re = re
+
+	return p, true
+}
+
+// match reports whether the pattern matches rel (slash-separated, relative to
+// the package root).
+func (p ignorePattern) match(rel string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+	if p.base != "" {
+		if !strings.HasPrefix(rel, p.base+"/") {
+			return false
+		}
+		rel = strings.TrimPrefix(rel, p.base+"/")
+	}
+	return p.re.MatchString(rel)
+}
+
+// globToRegexp converts a gitignore glob into a regular expression body.
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				switch {
+				case i+2 < len(glob) && glob[i+2] == '/':
+					sb.WriteString("(?:.*/)?")
+					i += 2
+				default:
+					sb.WriteString(".*")
+					i++
+				}
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				sb.WriteString(regexp.QuoteMeta(string(glob[i])))
+			}
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return sb.String()
+}
+
+// ignoreRules is an ordered list of patterns where the last match wins.
+type ignoreRules []ignorePattern
+
+// ignored reports whether rel is excluded by the rules.
+func (r ignoreRules) ignored(rel string, isDir bool) bool {
+	ignored := false
+	for _, p := range r {
+		if p.match(rel, isDir) {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
+
+// matchesPathOrParent reports whether rel or any of its parent directories
+// is matched by the rules.
+func (r ignoreRules) matchesPathOrParent(rel string) bool {
+	if r.ignored(rel, false) {
+		return true
+	}
+	for dir := path.Dir(rel); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if r.ignored(dir, true) {
+			return true
+		}
+	}
+	return false
+}
+
+// newIgnoreRules builds rules relative to base from a list of patterns.
+func newIgnoreRules(base string, lines []string) ignoreRules {
+	var rules ignoreRules
+	for _, line := range lines {
+		if p, ok := parseIgnorePattern(base, line); ok {
+			rules = append(rules, p)
+		}
+	}
+	return rules
+}
+
+// readIgnoreFile reads the patterns from an ignore file and reports whether
+// the file exists.
+func readIgnoreFile(file string) ([]string, bool) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, false
+	}
+	defer func() { _ = f.Close() }()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, true
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestIgnorePattern_Match(t *testing.T) {
+	tests := []struct {
+		pattern string
+		base    string
+		path    string
+		isDir   bool
+		want    bool
+	}{
+		{"*.log", "", "debug.log", false, true},
+		{"*.log", "", "logs/debug.log", false, true},
+		{"/debug.log", "", "logs/debug.log", false, false},
+		{"/debug.log", "", "debug.log", false, true},
+		{"build/", "", "build", true, true},
+		{"build/", "", "build", false, false},
+		{"doc/*.png", "", "doc/a.png", false, true},
+		{"doc/*.png", "", "doc/sub/a.png", false, false},
+		{"doc/**/*.png", "", "doc/sub/deep/a.png", false, true},
+		{"doc/**/*.png", "", "doc/a.png", false, true},
+		{"**/fixtures", "", "test/fixtures", true, true},
+		{"secret?.txt", "", "secret1.txt", false, true},
+		{"secret[0-9].txt", "", "secretA.txt", false, false},
+		{"secret[!0-9].txt", "", "secretA.txt", false, true},
+		{"*.g.dart", "lib", "lib/src/model.g.dart", false, true},
+		{"*.g.dart", "lib", "test/model.g.dart", false, false},
+		{"/gen", "lib", "lib/gen", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			p, ok := parseIgnorePattern(tt.base, tt.pattern)
+			if !ok {
+				t.Fatalf("failed to parse pattern %q", tt.pattern)
+			}
+			if got := p.match(tt.path, tt.isDir); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseIgnorePattern_Skips(t *testing.T) {
+	for _, line := range []string{"", "   ", "# comment", "!", "/"} {
+		if _, ok := parseIgnorePattern("", line); ok {
+			t.Errorf("expected %q to be skipped", line)
+		}
+	}
+}
+
+func TestIgnoreRules(t *testing.T) {
+	rules := newIgnoreRules("", []string{"*.log", "!keep.log", "build/"})
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"debug.log", true},
+		{"keep.log", false},
+		{"lib/main.dart", false},
+	}
+	for _, tt := range tests {
+		if got := rules.ignored(tt.path, false); got != tt.want {
+			t.Errorf("ignored(%s): expected %v, got %v", tt.path, tt.want, got)
+		}
+	}
+
+	if !rules.matchesPathOrParent("build/web/main.dart.js") {
+		t.Error("expected file under build/ to match")
+	}
+	if rules.matchesPathOrParent("lib/build.dart") {
+		t.Error("expected lib/build.dart not to match")
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// pub.dev metadata limits.
+const (
+	maxTopics                = 5
+	maxScreenshots           = 10
+	maxScreenshotSize        = 4 << 20
+	maxScreenshotDescription = 160
+)
+
+var (
+	topicPattern      = regexp.MustCompile(`^[a-z][a-z0-9-]{0,30}[a-z0-9]$`)
+	executablePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+	supportedPlatforms = map[string]bool{
+		"android": true,
+		"ios":     true,
+		"linux":   true,
+		"macos":   true,
+		"web":     true,
+		"windows": true,
+	}
+
+	screenshotExtensions = map[string]bool{
+		".gif":  true,
+		".jpeg": true,
+		".jpg":  true,
+		".png":  true,
+		".webp": true,
+	}
+
+	resolutionModes = map[string]bool{
+		"workspace": true,
+		"external":  true,
+	}
+)
+
+// validateMetadata checks the optional pub.dev metadata fields.
+func validateMetadata(l *issueList) {
+	pubspec := l.pubspec
+
+	urls := []struct {
+		field string
+		value string
+	}{
+		{"homepage", pubspec.Homepage},
+		{"repository", pubspec.Repository},
+		{"issue_tracker", pubspec.IssueTracker},
+		{"documentation", pubspec.Documentation},
+	}
+	for _, u := range urls {
+		if u.value == "" {
+			continue
+		}
+		if err := validateHTTPURL(u.value); err != nil {
+			l.errorf(u.field, "url-invalid", "%s %v", u.field, err)
+		}
+	}
+
+	for i, funding := range pubspec.Funding {
+		if err := validateHTTPURL(funding); err != nil {
+			l.errorf(fmt.Sprintf("funding[%d]", i), "url-invalid", "funding %v", err)
+		}
+	}
+
+	validateTopics(l)
+	validateScreenshots(l)
+	validatePlatforms(l)
+	validateFalseSecrets(l)
+	validatePublishTo(l)
+	validateExecutables(l)
+
+	if pubspec.Resolution != "" && !resolutionModes[pubspec.Resolution] {
+		l.errorf("resolution", "resolution-invalid",
+			"resolution must be one of: workspace, external (got %q)", pubspec.Resolution)
+	}
+}
+
+// validateHTTPURL checks that a value is an absolute http or https URL.
+func validateHTTPURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("%q is not a valid URL: %v", value, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%q must use http or https", value)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("%q is missing a host", value)
+	}
+	return nil
+}
+
+func validateTopics(l *issueList) {
+	topics := l.pubspec.Topics
+	if len(topics) > maxTopics {
+		l.errorf("topics", "topics-count", "at most %d topics are allowed (currently %d)", maxTopics, len(topics))
+	}
+
+	seen := make(map[string]bool)
+	for i, topic := range topics {
+		field := fmt.Sprintf("topics[%d]", i)
+		if !topicPattern.MatchString(topic) || strings.Contains(topic, "--") {
+			l.errorf(field, "topic-format",
+				"topic %q must be 2-32 lowercase alphanumeric characters or dashes, starting with a letter", topic)
+		}
+		if seen[topic] {
+			l.errorf(field, "topic-duplicate", "topic %q is listed more than once", topic)
+		}
+		seen[topic] = true
+	}
+}
+
+func validateScreenshots(l *issueList) {
+	screenshots := l.pubspec.Screenshots
+	if len(screenshots) > maxScreenshots {
+		l.errorf("screenshots", "screenshots-count",
+			"at most %d screenshots are allowed (currently %d)", maxScreenshots, len(screenshots))
+	}
+
+	for i, s := range screenshots {
+		field := fmt.Sprintf("screenshots[%d]", i)
+
+		if s.Description == "" {
+			l.errorf(field+".description", "screenshot-description",
+				"screenshot %q is missing a description", s.Path)
+		} else if len(s.Description) > maxScreenshotDescription {
+			l.errorf(field+".description", "screenshot-description",
+				"screenshot %q description should be at most %d characters (currently %d)",
+				s.Path, maxScreenshotDescription, len(s.Description))
+		}
+
+		if s.Path == "" {
+			l.errorf(field+".path", "screenshot-path", "screenshot %q is missing a path", s.Description)
+			continue
+		}
+		if filepath.IsAbs(s.Path) || strings.Contains(filepath.ToSlash(filepath.Clean(s.Path)), "..") {
+			l.errorf(field+".path", "screenshot-path", "screenshot %q must be a path inside the package", s.Path)
+			continue
+		}
+		if !screenshotExtensions[strings.ToLower(filepath.Ext(s.Path))] {
+			l.errorf(field+".path", "screenshot-format", "screenshot %q must be a png, jpg, gif or webp image", s.Path)
+			continue
+		}
+
+		info, err := os.Stat(filepath.Join(l.pubspec.dir, s.Path))
+		switch {
+		case err != nil:
+			l.errorf(field+".path", "screenshot-missing", "screenshot %q not found", s.Path)
+		case info.IsDir():
+			l.errorf(field+".path", "screenshot-missing", "screenshot %q is a directory", s.Path)
+		case info.Size() > maxScreenshotSize:
+			l.errorf(field+".path", "screenshot-size",
+				"screenshot %q exceeds 4 MB (currently %d bytes)", s.Path, info.Size())
+		}
+	}
+}
+
+func validatePlatforms(l *issueList) {
+	platforms := l.pubspec.Platforms
+	for _, name := range slices.Sorted(maps.Keys(platforms)) {
+		field := "platforms." + name
+		if !supportedPlatforms[name] {
+			l.errorf(field, "platform-unknown",
+				"platform %q is not supported (use android, ios, linux, macos, web or windows)", name)
+		}
+		if platforms[name] != nil {
+			l.errorf(field, "platform-value", "platform %q must not have a value", name)
+		}
+	}
+}
+
+func validateFalseSecrets(l *issueList) {
+	for i, pattern := range l.pubspec.FalseSecrets {
+		field := fmt.Sprintf("false_secrets[%d]", i)
+		if strings.TrimSpace(pattern) == "" {
+			l.errorf(field, "false-secrets-empty", "false_secrets entries must not be empty")
+		} else if strings.Contains(pattern, "..") {
+			l.errorf(field, "false-secrets-parent",
+				"false_secrets entry %q must not reference parent directories", pattern)
+		}
+	}
+}
+
+func validatePublishTo(l *issueList) {
+	publishTo := l.pubspec.PublishTo
+	if publishTo == "" || publishTo == "none" {
+		return
+	}
+	if err := validateHTTPURL(publishTo); err != nil {
+		l.errorf("publish_to", "publish-to-invalid", "publish_to must be \"none\" or a registry URL: %v", err)
+	}
+}
+
+func validateExecutables(l *issueList) {
+	executables := l.pubspec.Executables
+	for _, name := range slices.Sorted(maps.Keys(executables)) {
+		field := "executables." + name
+		script := executables[name]
+		if !executablePattern.MatchString(name) {
+			l.errorf(field, "executable-name",
+				"executable name %q may only contain letters, digits, '_' and '-'", name)
+			continue
+		}
+		if script == "" {
+			script = name
+		}
+		if !executablePattern.MatchString(script) {
+			l.errorf(field, "executable-script",
+				"executable %q script %q may only contain letters, digits, '_' and '-'", name, script)
+			continue
+		}
+
+		path := filepath.Join(l.pubspec.dir, "bin", script+".dart")
+		if _, err := os.Stat(path); err != nil {
+			l.warnf(field, "executable-missing", "executable %q refers to missing bin/%s.dart", name, script)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func validMetadataPubspec(dir string) *Pubspec {
+	return &Pubspec{
+		Name:        "my_package",
+		Version:     "1.0.0",
+		Description: "A package description that is long enough to pass the validation requirement of 60 chars",
+		Environment: map[string]string{"sdk": ">=3.0.0 <4.0.0"},
+		dir:         dir,
+	}
+}
+
+func TestValidatePubspec_Metadata(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "doc"), 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "doc", "screen.png"), []byte("png"), 0644); err != nil {
+		t.Fatalf("failed to write screenshot: %v", err)
+	}
+	bigPath := filepath.Join(dir, "doc", "big.png")
+	if err := os.WriteFile(bigPath, nil, 0644); err != nil {
+		t.Fatalf("failed to write screenshot: %v", err)
+	}
+	if err := os.Truncate(bigPath, maxScreenshotSize+1); err != nil {
+		t.Fatalf("failed to grow screenshot: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "bin"), 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bin", "tool.dart"), []byte("void main() {}\n"), 0644); err != nil {
+		t.Fatalf("failed to write executable: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		modify   func(p *Pubspec)
+		errMsg   string
+		severity Severity
+	}{
+		{
+			name: "valid metadata",
+			modify: func(p *Pubspec) {
+				p.Homepage = "https://example.dev"
+				p.Repository = "https://github.com/example/my_package"
+				p.IssueTracker = "https://github.com/example/my_package/issues"
+				p.Documentation = "https://example.dev/docs"
+				p.Topics = []string{"http", "network-io"}
+				p.Screenshots = []PubspecScreenshot{{Description: "Main screen", Path: "doc/screen.png"}}
+				p.Funding = []string{"https://github.com/sponsors/example"}
+				p.Platforms = map[string]any{"android": nil, "web": nil}
+				p.FalseSecrets = []string{"/test/fixtures/**"}
+				p.PublishTo = "https://pub.example.com"
+				p.Executables = map[string]string{"tool": "", "my-tool": "tool"}
+				p.Resolution = "workspace"
+			},
+		},
+		{
+			name:   "non-http repository",
+			modify: func(p *Pubspec) { p.Repository = "git@github.com:example/my_package.git" },
+			errMsg: "repository",
+		},
+		{
+			name:   "ftp documentation",
+			modify: func(p *Pubspec) { p.Documentation = "ftp://example.dev/docs" },
+			errMsg: "must use http or https",
+		},
+		{
+			name:   "invalid funding",
+			modify: func(p *Pubspec) { p.Funding = []string{"sponsor-me"} },
+			errMsg: "funding",
+		},
+		{
+			name:   "too many topics",
+			modify: func(p *Pubspec) { p.Topics = []string{"a1", "b2", "c3", "d4", "e5", "f6"} },
+			errMsg: "at most 5 topics",
+		},
+		{
+			name:   "uppercase topic",
+			modify: func(p *Pubspec) { p.Topics = []string{"Network"} },
+			errMsg: `topic "Network"`,
+		},
+		{
+			name:   "double dash topic",
+			modify: func(p *Pubspec) { p.Topics = []string{"network--io"} },
+			errMsg: `topic "network--io"`,
+		},
+		{
+			name:   "duplicate topic",
+			modify: func(p *Pubspec) { p.Topics = []string{"http", "http"} },
+			errMsg: "more than once",
+		},
+		{
+			name: "missing screenshot",
+			modify: func(p *Pubspec) {
+				p.Screenshots = []PubspecScreenshot{{Description: "Missing", Path: "doc/missing.png"}}
+			},
+			errMsg: "not found",
+		},
+		{
+			name: "screenshot outside package",
+			modify: func(p *Pubspec) {
+				p.Screenshots = []PubspecScreenshot{{Description: "Escape", Path: "../screen.png"}}
+			},
+			errMsg: "inside the package",
+		},
+		{
+			name: "oversized screenshot",
+			modify: func(p *Pubspec) {
+				p.Screenshots = []PubspecScreenshot{{Description: "Big", Path: "doc/big.png"}}
+			},
+			errMsg: "exceeds 4 MB",
+		},
+		{
+			name: "screenshot without description",
+			modify: func(p *Pubspec) {
+				p.Screenshots = []PubspecScreenshot{{Path: "doc/screen.png"}}
+			},
+			errMsg: "missing a description",
+		},
+		{
+			name: "screenshot wrong type",
+			modify: func(p *Pubspec) {
+				p.Screenshots = []PubspecScreenshot{{Description: "Video", Path: "doc/screen.mp4"}}
+			},
+			errMsg: "png, jpg, gif or webp",
+		},
+		{
+			name:   "unknown platform",
+			modify: func(p *Pubspec) { p.Platforms = map[string]any{"fuchsia": nil} },
+			errMsg: `platform "fuchsia"`,
+		},
+		{
+			name:   "platform with value",
+			modify: func(p *Pubspec) { p.Platforms = map[string]any{"ios": "yes"} },
+			errMsg: "must not have a value",
+		},
+		{
+			name:   "empty false secret",
+			modify: func(p *Pubspec) { p.FalseSecrets = []string{" "} },
+			errMsg: "false_secrets",
+		},
+		{
+			name:   "invalid publish_to",
+			modify: func(p *Pubspec) { p.PublishTo = "nowhere" },
+			errMsg: "publish_to",
+		},
+		{
+			name:     "missing executable script",
+			modify:   func(p *Pubspec) { p.Executables = map[string]string{"other": ""} },
+			errMsg:   "bin/other.dart",
+			severity: SeverityWarning,
+		},
+		{
+			name:   "invalid resolution",
+			modify: func(p *Pubspec) { p.Resolution = "local" },
+			errMsg: "resolution",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pubspec := validMetadataPubspec(dir)
+			tt.modify(pubspec)
+
+			issues := ValidatePubspec(pubspec)
+
+			if tt.errMsg == "" {
+				if len(issues) != 0 {
+					t.Errorf("unexpected issues: %v", issues)
+				}
+				return
+			}
+			if len(issues) != 1 {
+				t.Fatalf("expected 1 issue containing %q, got %v", tt.errMsg, issues)
+			}
+			if !strings.Contains(issues[0].Message, tt.errMsg) {
+				t.Errorf("expected issue containing %q, got %q", tt.errMsg, issues[0].Message)
+			}
+			severity := tt.severity
+			if severity == "" {
+				severity = SeverityError
+			}
+			if issues[0].Severity != severity {
+				t.Errorf("expected severity %s, got %s", severity, issues[0].Severity)
+			}
+		})
+	}
+}
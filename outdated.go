@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Dependency kinds reported by pub outdated.
+const (
+	DependencyKindDirect     = "direct"
+	DependencyKindDev        = "dev"
+	DependencyKindTransitive = "transitive"
+)
+
+// OutdatedReport represents the output of `dart pub outdated --json`.
+type OutdatedReport struct {
+	Packages []OutdatedPackage `json:"packages"`
+}
+
+// OutdatedPackage describes a single dependency in the outdated report.
+type OutdatedPackage struct {
+	Package                string           `json:"package"`
+	Kind                   string           `json:"kind"`
+	IsDiscontinued         bool             `json:"isDiscontinued"`
+	DiscontinuedReplacedBy string           `json:"discontinuedReplacedBy,omitempty"`
+	IsCurrentRetracted     bool             `json:"isCurrentRetracted"`
+	Current                *OutdatedVersion `json:"current"`
+	Upgradable             *OutdatedVersion `json:"upgradable"`
+	Resolvable             *OutdatedVersion `json:"resolvable"`
+	Latest                 *OutdatedVersion `json:"latest"`
+}
+
+// OutdatedVersion holds a version entry in the outdated report.
+type OutdatedVersion struct {
+	Version string `json:"version"`
+}
+
+// OutdatedFinding is a dependency health problem found in the outdated report.
+type OutdatedFinding struct {
+	Package string
+	Policy  string
+	Message string
+}
+
+// ParseOutdated parses the JSON output of `dart pub outdated --json`.
+func ParseOutdated(data []byte) (*OutdatedReport, error) {
+	var report OutdatedReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse pub outdated output: %w", err)
+	}
+	return &report, nil
+}
+
+// EvaluateOutdated checks the report against the configured policies.
+func EvaluateOutdated(report *OutdatedReport, cfg OutdatedConfig) []OutdatedFinding {
+	var findings []OutdatedFinding
+
+	for _, pkg := range report.Packages {
+		if !cfg.includesKind(pkg.Kind) {
+			continue
+		}
+
+		if pkg.IsDiscontinued && cfg.Discontinued != PolicyIgnore {
+			msg := fmt.Sprintf("%s is discontinued", pkg.Package)
+			if pkg.DiscontinuedReplacedBy != "" {
+				msg = fmt.Sprintf("%s (replaced by %s)", msg, pkg.DiscontinuedReplacedBy)
+			}
+			findings = append(findings, OutdatedFinding{
+				Package: pkg.Package,
+				Policy:  cfg.Discontinued,
+				Message: msg,
+			})
+		}
+
+		if pkg.IsCurrentRetracted && cfg.Retracted != PolicyIgnore {
+			findings = append(findings, OutdatedFinding{
+				Package: pkg.Package,
+				Policy:  cfg.Retracted,
+				Message: fmt.Sprintf("%s %s is retracted", pkg.Package, versionOf(pkg.Current)),
+			})
+		}
+
+		if cfg.MajorBehind != PolicyIgnore && pkg.Current != nil && pkg.Latest != nil {
+			if isBreakingBehind(pkg.Current.Version, pkg.Latest.Version) {
+				findings = append(findings, OutdatedFinding{
+					Package: pkg.Package,
+					Policy:  cfg.MajorBehind,
+					Message: fmt.Sprintf("%s %s is a major version behind latest %s",
+						pkg.Package, pkg.Current.Version, pkg.Latest.Version),
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
+// FormatOutdatedTable renders the report as a plain text table.
+func FormatOutdatedTable(report *OutdatedReport) string {
+	rows := [][]string{{"Package", "Kind", "Current", "Upgradable", "Resolvable", "Latest"}}
+	for _, pkg := range report.Packages {
+		name := pkg.Package
+		if pkg.IsDiscontinued {
+			name += " (discontinued)"
+		}
+		rows = append(rows, []string{
+			name,
+			pkg.Kind,
+			versionOf(pkg.Current),
+			versionOf(pkg.Upgradable),
+			versionOf(pkg.Resolvable),
+			versionOf(pkg.Latest),
+		})
+	}
+
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, col := range row {
+			widths[i] = max(widths[i], len(col))
+		}
+	}
+
+	var sb strings.Builder
+	for _, row := range rows {
+		for i, col := range row {
+			if i > 0 {
+				sb.WriteString("  ")
+			}
+			if i == len(row)-1 {
+				sb.WriteString(col)
+			} else {
+				sb.WriteString(fmt.Sprintf("%-*s", widths[i], col))
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+func (c OutdatedConfig) includesKind(kind string) bool {
+	switch kind {
+	case DependencyKindDirect:
+		return true
+	case DependencyKindDev:
+		return c.IncludeDev
+	case DependencyKindTransitive:
+		return c.IncludeTransitive
+	default:
+		return false
+	}
+}
+
+func versionOf(v *OutdatedVersion) string {
+	if v == nil || v.Version == "" {
+		return "-"
+	}
+	return v.Version
+}
+
+// isBreakingBehind reports whether latest is a breaking release ahead of current.
+// Following pub semantics, for 0.x versions the minor component is breaking.
+func isBreakingBehind(current, latest string) bool {
+	cur, err := ParseVersion(current)
+	if err != nil {
+		return false
+	}
+	lat, err := ParseVersion(latest)
+	if err != nil {
+		return false
+	}
+
+	release := SemVer{Major: lat.Major, Minor: lat.Minor, Patch: lat.Patch}
+	return release.Compare(cur.NextBreaking()) >= 0
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const outdatedJSON = `{
+  "packages": [
+    {
+      "package": "http",
+      "kind": "direct",
+      "isDiscontinued": false,
+      "isCurrentRetracted": false,
+      "current": {"version": "0.13.6"},
+      "upgradable": {"version": "0.13.6"},
+      "resolvable": {"version": "1.2.0"},
+      "latest": {"version": "1.2.0"}
+    },
+    {
+      "package": "pedantic",
+      "kind": "dev",
+      "isDiscontinued": true,
+      "discontinuedReplacedBy": "lints",
+      "isCurrentRetracted": false,
+      "current": {"version": "1.11.1"},
+      "upgradable": {"version": "1.11.1"},
+      "resolvable": {"version": "1.11.1"},
+      "latest": {"version": "1.11.1"}
+    },
+    {
+      "package": "meta",
+      "kind": "transitive",
+      "isDiscontinued": false,
+      "isCurrentRetracted": false,
+      "current": {"version": "1.9.0"},
+      "upgradable": {"version": "1.9.1"},
+      "resolvable": {"version": "1.9.1"},
+      "latest": {"version": "1.15.0"}
+    },
+    {
+      "package": "yaml",
+      "kind": "direct",
+      "isDiscontinued": true,
+      "isCurrentRetracted": false,
+      "current": null,
+      "upgradable": null,
+      "resolvable": {"version": "3.1.2"},
+      "latest": {"version": "3.1.2"}
+    }
+  ]
+}`
+
+func TestParseOutdated(t *testing.T) {
+	report, err := ParseOutdated([]byte(outdatedJSON))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(report.Packages) != 4 {
+		t.Fatalf("expected 4 packages, got %d", len(report.Packages))
+	}
+	if report.Packages[1].DiscontinuedReplacedBy != "lints" {
+		t.Errorf("expected replacement 'lints', got %s", report.Packages[1].DiscontinuedReplacedBy)
+	}
+	if report.Packages[3].Current != nil {
+		t.Errorf("expected nil current version, got %+v", report.Packages[3].Current)
+	}
+
+	if _, err := ParseOutdated([]byte(`{invalid`)); err == nil {
+		t.Error("expected error for invalid json")
+	}
+}
+
+func TestEvaluateOutdated(t *testing.T) {
+	report, err := ParseOutdated([]byte(outdatedJSON))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		cfg      OutdatedConfig
+		expected map[string]string
+	}{
+		{
+			name: "direct only",
+			cfg: OutdatedConfig{
+				Discontinued: PolicyFail,
+				Retracted:    PolicyFail,
+				MajorBehind:  PolicyWarn,
+			},
+			expected: map[string]string{
+				"http": PolicyWarn,
+				"yaml": PolicyFail,
+			},
+		},
+		{
+			name: "include dev and transitive",
+			cfg: OutdatedConfig{
+				Discontinued:      PolicyWarn,
+				Retracted:         PolicyFail,
+				MajorBehind:       PolicyFail,
+				IncludeDev:        true,
+				IncludeTransitive: true,
+			},
+			expected: map[string]string{
+				"http":     PolicyFail,
+				"pedantic": PolicyWarn,
+				"yaml":     PolicyWarn,
+			},
+		},
+		{
+			name: "all ignored",
+			cfg: OutdatedConfig{
+				Discontinued:      PolicyIgnore,
+				Retracted:         PolicyIgnore,
+				MajorBehind:       PolicyIgnore,
+				IncludeDev:        true,
+				IncludeTransitive: true,
+			},
+			expected: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := EvaluateOutdated(report, tt.cfg)
+
+			got := make(map[string]string)
+			for _, f := range findings {
+				got[f.Package] = f.Policy
+			}
+
+			if len(got) != len(tt.expected) {
+				t.Errorf("expected findings %v, got %v", tt.expected, got)
+			}
+			for pkg, policy := range tt.expected {
+				if got[pkg] != policy {
+					t.Errorf("expected %s policy %q, got %q", pkg, policy, got[pkg])
+				}
+			}
+		})
+	}
+}
+
+func TestFormatOutdatedTable(t *testing.T) {
+	report, err := ParseOutdated([]byte(outdatedJSON))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	table := FormatOutdatedTable(report)
+	lines := strings.Split(strings.TrimSpace(table), "\n")
+
+	if len(lines) != 5 {
+		t.Fatalf("expected 5 lines, got %d:\n%s", len(lines), table)
+	}
+	if !strings.HasPrefix(lines[0], "Package") {
+		t.Errorf("expected header row, got %q", lines[0])
+	}
+	if !strings.Contains(table, "pedantic (discontinued)") {
+		t.Errorf("expected discontinued marker in table:\n%s", table)
+	}
+	if !strings.Contains(lines[4], "-") {
+		t.Errorf("expected placeholder for missing version, got %q", lines[4])
+	}
+}
+
+func TestIsBreakingBehind(t *testing.T) {
+	tests := []struct {
+		current  string
+		latest   string
+		expected bool
+	}{
+		{"1.0.0", "2.0.0", true},
+		{"1.0.0", "1.5.0", false},
+		{"0.13.6", "0.14.0", true},
+		{"0.13.6", "0.13.7", false},
+		{"2.0.0", "2.0.0", false},
+		{"1.0.0+1", "2.0.0-dev.1", true},
+		{"invalid", "2.0.0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.current+"->"+tt.latest, func(t *testing.T) {
+			if got := isBreakingBehind(tt.current, tt.latest); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CoverageDir is the package-relative directory dart test writes coverage to.
+// It lives in StateDir so clearing it never touches the package's own files.
+const CoverageDir = StateDir + "/coverage"
+
+// StepOutput is the structured form of a StepResult in response outputs.
+type StepOutput struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	DurationMS int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+func stepOutputs(results []StepResult) []StepOutput {
+	steps := make([]StepOutput, len(results))
+	for i, res := range results {
+		steps[i] = StepOutput{
+			Name:       res.Name,
+			Status:     string(res.Status),
+			DurationMS: res.Duration.Milliseconds(),
+			Error:      res.Error,
+		}
+	}
+	return steps
+}
+
+// AttemptOutput is the structured form of a PublishAttempt in response outputs.
+type AttemptOutput struct {
+	Number     int    `json:"number"`
+	DurationMS int64  `json:"duration_ms"`
+	Kind       string `json:"kind,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+func attemptOutputs(attempts []PublishAttempt) []AttemptOutput {
+	out := make([]AttemptOutput, len(attempts))
+	for i, a := range attempts {
+		out[i] = AttemptOutput{
+			Number:     a.Number,
+			DurationMS: a.Duration.Milliseconds(),
+			Kind:       string(a.Kind),
+			Error:      a.Error,
+		}
+	}
+	return out
+}
+
+// ArchiveOutput is the structured form of an ArchiveReport in response outputs.
+type ArchiveOutput struct {
+	SHA256       string `json:"sha256"`
+	Files        int    `json:"files"`
+	Size         int64  `json:"size"`
+	UnpackedSize int64  `json:"unpacked_size"`
+}
+
+func archiveOutput(report *ArchiveReport) *ArchiveOutput {
+	return &ArchiveOutput{
+		SHA256:       report.SHA256,
+		Files:        len(report.Files),
+		Size:         report.CompressedSize,
+		UnpackedSize: report.TotalSize,
+	}
+}
+
+// Finding is a problem or summary reported while running a step or command.
+type Finding struct {
+	Step     string   `json:"step"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// noteFindings turns the notes of a step into findings. Notes starting with
+// "warning: " are warnings, the rest are summaries.
+func noteFindings(step string, notes []string) []Finding {
+	var findings []Finding
+	for _, note := range notes {
+		finding := Finding{Step: step, Severity: SeverityInfo, Message: note}
+		if msg, ok := strings.CutPrefix(note, "warning: "); ok {
+			finding.Severity = SeverityWarning
+			finding.Message = msg
+		}
+		findings = append(findings, finding)
+	}
+	return findings
+}
+
+// stepFindings collects the notes and failures of the steps. A failure the
+// pipeline continued past is a warning.
+func stepFindings(results []StepResult) []Finding {
+	var findings []Finding
+	for _, res := range results {
+		findings = append(findings, noteFindings(res.Name, res.Notes)...)
+		if res.Status != StepFailed {
+			continue
+		}
+		severity := SeverityError
+		if res.ContinueOnError {
+			severity = SeverityWarning
+		}
+		findings = append(findings, Finding{Step: res.Name, Severity: severity, Message: res.Error})
+	}
+	return findings
+}
+
+// AnalyzerSummary counts the issues reported by dart analyze.
+type AnalyzerSummary struct {
+	Issues   int `json:"issues"`
+	Errors   int `json:"errors"`
+	Warnings int `json:"warnings"`
+	Infos    int `json:"infos"`
+}
+
+var (
+	analyzerIssuePattern   = regexp.MustCompile(`(?m)^\s*(error|warning|info) - `)
+	analyzerSummaryPattern = regexp.MustCompile(`(?m)^(?:(\d+) issues? found|No issues found)`)
+)
+
+// ParseAnalyzeOutput summarizes dart analyze output. It returns nil if the
+// output has no summary line, for example because analysis did not run.
+func ParseAnalyzeOutput(output string) *AnalyzerSummary {
+	m := analyzerSummaryPattern.FindStringSubmatch(output)
+	if m == nil {
+		return nil
+	}
+
+	summary := &AnalyzerSummary{}
+	summary.Issues, _ = strconv.Atoi(m[1])
+	for _, issue := range analyzerIssuePattern.FindAllStringSubmatch(output, -1) {
+		switch issue[1] {
+		case "error":
+			summary.Errors++
+		case "warning":
+			summary.Warnings++
+		case "info":
+			summary.Infos++
+		}
+	}
+	return summary
+}
+
+// TestSummary counts the tests reported by dart test or flutter test.
+type TestSummary struct {
+	Passed  int `json:"passed"`
+	Skipped int `json:"skipped"`
+	Failed  int `json:"failed"`
+}
+
+// testProgressPattern matches the test runner's progress counters, such as
+// "+12 ~1 -2: Some tests failed."
+var testProgressPattern = regexp.MustCompile(`\+(\d+)(?: ~(\d+))?(?: -(\d+))?: `)
+
+// ParseTestOutput summarizes test runner output from its last progress
+// line. It returns nil if there is none.
+func ParseTestOutput(output string) *TestSummary {
+	matches := testProgressPattern.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	m := matches[len(matches)-1]
+	summary := &TestSummary{}
+	summary.Passed, _ = strconv.Atoi(m[1])
+	summary.Skipped, _ = strconv.Atoi(m[2])
+	summary.Failed, _ = strconv.Atoi(m[3])
+	return summary
+}
+
+// CoverageSummary is the line coverage of the package's own libraries.
+type CoverageSummary struct {
+	Lines   int     `json:"lines"`
+	Covered int     `json:"covered"`
+	Percent float64 `json:"percent"`
+}
+
+// ReadCoverage computes line coverage of package pkg from the JSON hit maps
+// dart test writes to dir. It returns nil if there are none.
+func ReadCoverage(dir, pkg string) (*CoverageSummary, error) {
+	prefix := "package:" + pkg + "/"
+	hits := make(map[string]map[int]bool)
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".json" {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		var report struct {
+			Coverage []struct {
+				Source string `json:"source"`
+				Hits   []any  `json:"hits"`
+			} `json:"coverage"`
+		}
+		if err := json.Unmarshal(data, &report); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+
+		for _, entry := range report.Coverage {
+			if !strings.HasPrefix(entry.Source, prefix) {
+				continue
+			}
+			lines := hits[entry.Source]
+			if lines == nil {
+				lines = make(map[int]bool)
+				hits[entry.Source] = lines
+			}
+			addHits(lines, entry.Hits)
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read coverage: %w", err)
+	}
+	if len(hits) == 0 {
+		return nil, nil
+	}
+
+	summary := &CoverageSummary{}
+	for _, lines := range hits {
+		for _, hit := range lines {
+			summary.Lines++
+			if hit {
+				summary.Covered++
+			}
+		}
+	}
+	if summary.Lines > 0 {
+		summary.Percent = math.Round(float64(summary.Covered)*1000/float64(summary.Lines)) / 10
+	}
+	return summary, nil
+}
+
+// addHits merges a hit map, a flat list of line and count pairs, into
+// lines. Lines may also be "start-end" ranges.
+func addHits(lines map[int]bool, pairs []any) {
+	for i := 0; i+1 < len(pairs); i += 2 {
+		count, _ := pairs[i+1].(float64)
+		var start, end int
+		switch line := pairs[i].(type) {
+		case float64:
+			start, end = int(line), int(line)
+		case string:
+			from, to, isRange := strings.Cut(line, "-")
+			start, _ = strconv.Atoi(from)
+			end = start
+			if isRange {
+				end, _ = strconv.Atoi(to)
+			}
+		}
+		for l := start; l <= end; l++ {
+			lines[l] = lines[l] || count > 0
+		}
+	}
+}
+
+// publishedPageURL returns the pub.dev page of a version. Other registries
+// have no common page URL scheme, so it is empty for them.
+func publishedPageURL(registry, pkg, version string) string {
+	if registry != DefaultRegistryURL {
+		return ""
+	}
+	return fmt.Sprintf("%s/packages/%s/versions/%s", DefaultRegistryURL, pkg, version)
+}
+
+// outputs returns the structured outputs of a pipeline run: the release,
+// every step's status and duration, the steps' findings, and the analyzer,
+// test, coverage and archive summaries when those steps ran.
+func (r *releaseRun) outputs(results []StepResult) map[string]any {
+	outputs := map[string]any{
+		"package": r.pubspec.Name,
+		"version": r.release.Version,
+		"flutter": r.isFlutter,
+		"steps":   stepOutputs(results),
+	}
+	if findings := stepFindings(results); len(findings) > 0 {
+		outputs["findings"] = findings
+	}
+	// The steps have finished, so the archive is no longer being built
+	if r.files.archive != nil {
+		outputs["archive"] = archiveOutput(r.files.archive)
+	}
+
+	for _, res := range results {
+		switch res.Name {
+		case "analyze":
+			if summary := ParseAnalyzeOutput(res.Output); summary != nil {
+				outputs["analyzer"] = summary
+			}
+		case "test":
+			if summary := ParseTestOutput(res.Output); summary != nil {
+				outputs["tests"] = summary
+			}
+			if res.Status != StepPassed || !r.cfg.TestConfig.Coverage || r.isFlutter {
+				continue
+			}
+			coverage, err := ReadCoverage(filepath.Join(r.packageDir, filepath.FromSlash(CoverageDir)), r.pubspec.Name)
+			if err != nil {
+				r.logger.Warn("Failed to summarize coverage", "error", err)
+			} else if coverage != nil {
+				outputs["coverage"] = coverage
+			}
+		}
+	}
+
+	return outputs
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseAnalyzeOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   *AnalyzerSummary
+	}{
+		{
+			name: "issues",
+			output: `Analyzing my_pkg...
+
+  error - lib/src/client.dart:12:5 - Undefined name 'foo'. - undefined_identifier
+warning - lib/src/client.dart:3:8 - Unused import: 'dart:io'. - unused_import
+   info - lib/my_pkg.dart:1:1 - Missing documentation. - public_member_api_docs
+   info - lib/my_pkg.dart:4:1 - Missing documentation. - public_member_api_docs
+
+4 issues found.
+`,
+			want: &AnalyzerSummary{Issues: 4, Errors: 1, Warnings: 1, Infos: 2},
+		},
+		{
+			name:   "single issue",
+			output: "   info - lib/a.dart:1:1 - Sort imports. - directives_ordering\n\n1 issue found.\n",
+			want:   &AnalyzerSummary{Issues: 1, Infos: 1},
+		},
+		{
+			name:   "clean",
+			output: "Analyzing my_pkg...\nNo issues found!\n",
+			want:   &AnalyzerSummary{},
+		},
+		{
+			name:   "no summary",
+			output: "Could not find a file named \"pubspec.yaml\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseAnalyzeOutput(tt.output)
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("expected no summary, got %+v", got)
+				}
+				return
+			}
+			if got == nil || *got != *tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseTestOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   *TestSummary
+	}{
+		{
+			name:   "all passed",
+			output: "00:00 +0: loading test/a_test.dart\n00:01 +11: parses\n00:01 +12: All tests passed!\n",
+			want:   &TestSummary{Passed: 12},
+		},
+		{
+			name:   "failures and skips",
+			output: "00:01 +3 ~1: ok\n00:02 +3 ~1 -1: fails [E]\n00:02 +5 ~1 -2: Some tests failed.\n",
+			want:   &TestSummary{Passed: 5, Skipped: 1, Failed: 2},
+		},
+		{
+			name:   "no progress",
+			output: "Error: Couldn't resolve the package 'test'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseTestOutput(tt.output)
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("expected no summary, got %+v", got)
+				}
+				return
+			}
+			if got == nil || *got != *tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestReadCoverage(t *testing.T) {
+	dir := t.TempDir()
+	writeCoverage := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, "test", name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Two test files covering different lines of the same library
+	writeCoverage("a_test.dart.vm.json", `{"type":"CodeCoverage","coverage":[
+		{"source":"package:my_pkg/src/a.dart","hits":[1,1,2,0,3,0,4,2]},
+		{"source":"package:test_api/src/test.dart","hits":[1,0,2,0]}
+	]}`)
+	writeCoverage("b_test.dart.vm.json", `{"type":"CodeCoverage","coverage":[
+		{"source":"package:my_pkg/src/a.dart","hits":[2,3,3,0]},
+		{"source":"package:my_pkg/src/b.dart","hits":["10-12",1,13,0]}
+	]}`)
+
+	got, err := ReadCoverage(dir, "my_pkg")
+	if err != nil {
+		t.Fatalf("ReadCoverage failed: %v", err)
+	}
+	// a.dart: lines 1, 2, 4 of 4 covered; b.dart: lines 10-12 of 4 covered
+	want := CoverageSummary{Lines: 8, Covered: 6, Percent: 75}
+	if got == nil || *got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	if got, err := ReadCoverage(filepath.Join(dir, "missing"), "my_pkg"); err != nil || got != nil {
+		t.Errorf("expected no coverage for a missing directory, got %+v, %v", got, err)
+	}
+	if got, err := ReadCoverage(dir, "other_pkg"); err != nil || got != nil {
+		t.Errorf("expected no coverage for another package, got %+v, %v", got, err)
+	}
+}
+
+func TestReleaseRun_Outputs(t *testing.T) {
+	dir := t.TempDir()
+	coverage := `{"coverage":[{"source":"package:test_package/a.dart","hits":[1,1,2,0]}]}`
+	if err := os.MkdirAll(filepath.Join(dir, filepath.FromSlash(CoverageDir)), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(CoverageDir), "a.json"), []byte(coverage), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := (&PubPlugin{}).parseConfig(map[string]any{"test_config": map[string]any{"coverage": true}})
+	r := newTestRun(dir, cfg)
+	r.files.archive = &ArchiveReport{Files: make([]PackageFile, 3), TotalSize: 4096, CompressedSize: 1200, SHA256: "ab12"}
+	results := []StepResult{
+		{Name: "analyze", Status: StepPassed, Duration: 1500 * time.Millisecond, Output: "No issues found!\n"},
+		{Name: "test", Status: StepPassed, Duration: 3 * time.Second, Output: "00:03 +7: All tests passed!\n"},
+		{Name: "dry_run_validate", Status: StepFailed, Duration: 20 * time.Millisecond, Error: "exit status 65"},
+	}
+
+	data, err := json.Marshal(r.outputs(results))
+	if err != nil {
+		t.Fatalf("outputs must be JSON encodable: %v", err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"analyzer":{"errors":0,"infos":0,"issues":0,"warnings":0},` +
+		`"archive":{"files":3,"sha256":"ab12","size":1200,"unpacked_size":4096},` +
+		`"coverage":{"covered":1,"lines":2,"percent":50},` +
+		`"findings":[{"message":"exit status 65","severity":"error","step":"dry_run_validate"}],` +
+		`"flutter":false,"package":"test_package",` +
+		`"steps":[{"duration_ms":1500,"name":"analyze","status":"passed"},` +
+		`{"duration_ms":3000,"name":"test","status":"passed"},` +
+		`{"duration_ms":20,"error":"exit status 65","name":"dry_run_validate","status":"failed"}],` +
+		`"tests":{"failed":0,"passed":7,"skipped":0},"version":"1.1.0"}`
+	normalized, _ := json.Marshal(got)
+	if string(normalized) != want {
+		t.Errorf("unexpected outputs:\n%s\nwant:\n%s", normalized, want)
+	}
+}
+
+func TestStepFindings(t *testing.T) {
+	results := []StepResult{
+		{Name: "outdated", Status: StepPassed, Notes: []string{"warning: http is 2 major versions behind", "Package  Current"}},
+		{Name: "pana", Status: StepFailed, Error: "pub points 120 below 130", ContinueOnError: true},
+		{Name: "test", Status: StepFailed, Error: "exit status 1"},
+		{Name: "git_commit", Status: StepSkipped},
+	}
+
+	want := []Finding{
+		{Step: "outdated", Severity: SeverityWarning, Message: "http is 2 major versions behind"},
+		{Step: "outdated", Severity: SeverityInfo, Message: "Package  Current"},
+		{Step: "pana", Severity: SeverityWarning, Message: "pub points 120 below 130"},
+		{Step: "test", Severity: SeverityError, Message: "exit status 1"},
+	}
+	got := stepFindings(results)
+	if len(got) != len(want) {
+		t.Fatalf("expected %d findings, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("finding %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestPublishedPageURL(t *testing.T) {
+	if got := publishedPageURL(DefaultRegistryURL, "my_pkg", "1.2.0"); got != "https://pub.dev/packages/my_pkg/versions/1.2.0" {
+		t.Errorf("unexpected pub.dev URL: %s", got)
+	}
+	if got := publishedPageURL("https://pub.example.com", "my_pkg", "1.2.0"); got != "" {
+		t.Errorf("expected no page URL for a private registry, got %s", got)
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	licenseFileNames = []string{"LICENSE", "LICENSE.md", "LICENSE.txt", "LICENCE", "LICENCE.md", "LICENCE.txt", "COPYING"}
+
+	// licenseMarkers identifies common OSI-approved licenses by distinctive phrases.
+	licenseMarkers = []struct {
+		name    string
+		phrases []string
+	}{
+		{"Apache-2.0", []string{"apache license", "version 2.0"}},
+		{"AGPL-3.0", []string{"gnu affero general public license"}},
+		{"LGPL", []string{"gnu lesser general public license"}},
+		{"GPL", []string{"gnu general public license"}},
+		{"MPL-2.0", []string{"mozilla public license", "2.0"}},
+		{"BSD-3-Clause", []string{"redistribution and use in source and binary forms", "neither the name"}},
+		{"BSD-2-Clause", []string{"redistribution and use in source and binary forms"}},
+		{"MIT", []string{"permission is hereby granted, free of charge"}},
+		{"ISC", []string{"permission to use, copy, modify, and/or distribute this software"}},
+		{"BSL-1.0", []string{"boost software license"}},
+		{"Zlib", []string{"this software is provided 'as-is'"}},
+		{"Unlicense", []string{"this is free and unencumbered software"}},
+	}
+
+	markdownImagePattern = regexp.MustCompile(`!\[[^\]]*\]\(\s*<?([^)\s>]+)`)
+	htmlImagePattern     = regexp.MustCompile(`(?i)<img[^>]*\ssrc\s*=\s*["']([^"']+)["']`)
+)
+
+// exampleEntryPoints lists the files pub.dev looks for, in order, when
+// showing a package example. {name} is replaced with the package name.
+var exampleEntryPoints = []string{
+	"example/example.md",
+	"example/README.md",
+	"example/main.dart",
+	"example/lib/main.dart",
+	"example/bin/main.dart",
+	"example/{name}.dart",
+	"example/{name}_example.dart",
+	"example/lib/{name}.dart",
+	"example/lib/{name}_example.dart",
+	"example/example.dart",
+	"example/lib/example.dart",
+	"example/bin/example.dart",
+}
+
+// CheckPackageFiles verifies README.md, LICENSE and the example in the package
+// root and reports each problem at the severity configured for it.
+func CheckPackageFiles(dir, packageName string, cfg PackageFilesConfig) []ValidationIssue {
+	var issues []ValidationIssue
+	add := func(policy, field, file, rule string, line int, format string, args ...any) {
+		severity := SeverityError
+		switch policy {
+		case PolicyIgnore:
+			return
+		case PolicyWarn:
+			severity = SeverityWarning
+		}
+		column := 0
+		if line > 0 {
+			column = 1
+		}
+		issues = append(issues, ValidationIssue{
+			Field:    "package_files." + field,
+			File:     file,
+			Rule:     rule,
+			Severity: severity,
+			Message:  fmt.Sprintf(format, args...),
+			Line:     line,
+			Column:   column,
+		})
+	}
+
+	readmePath := filepath.Join(dir, "README.md")
+	if _, err := os.Stat(readmePath); err != nil {
+		add(cfg.Readme, "readme", "README.md", "readme-missing", 0, "README.md is missing from the package root")
+	} else if cfg.ReadmeImages != PolicyIgnore {
+		images, err := readmeImages(readmePath)
+		if err != nil {
+			add(cfg.Readme, "readme", "README.md", "readme-unreadable", 0, "failed to read README.md: %v", err)
+		}
+		for _, img := range images {
+			switch {
+			case strings.HasPrefix(img.url, "https://"), strings.HasPrefix(img.url, "data:"):
+			case strings.HasPrefix(img.url, "http://"):
+				add(cfg.ReadmeImages, "readme_images", "README.md", "readme-image-insecure", img.line,
+					"image %s uses http; pub.dev only displays https images", img.url)
+			default:
+				add(cfg.ReadmeImages, "readme_images", "README.md", "readme-image-relative", img.line,
+					"image %s uses a relative URL; use an absolute https URL so it renders on pub.dev", img.url)
+			}
+		}
+	}
+
+	if license, name := findLicense(dir); license == "" {
+		add(cfg.License, "license", "LICENSE", "license-missing", 0, "LICENSE file is missing from the package root")
+	} else if name == "" {
+		add(cfg.License, "license", license, "license-unrecognized", 0,
+			"%s does not contain a recognized OSI-approved license", license)
+	}
+
+	if findExample(dir, packageName) == "" {
+		add(cfg.Example, "example", "example/", "example-missing", 0,
+			"no example found; add example/main.dart or example/README.md")
+	}
+
+	return issues
+}
+
+// findLicense returns the license file name and the detected license, if any.
+func findLicense(dir string) (file, license string) {
+	for _, name := range licenseFileNames {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		return name, DetectLicense(string(data))
+	}
+	return "", ""
+}
+
+// DetectLicense returns the identifier of the OSI-approved license in text,
+// or an empty string if none is recognized.
+func DetectLicense(text string) string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(text), " "))
+	for _, marker := range licenseMarkers {
+		matched := true
+		for _, phrase := range marker.phrases {
+			if !strings.Contains(normalized, phrase) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return marker.name
+		}
+	}
+	return ""
+}
+
+// findExample returns the first example entry point that exists.
+func findExample(dir, packageName string) string {
+	for _, candidate := range exampleEntryPoints {
+		path := strings.ReplaceAll(candidate, "{name}", packageName)
+		if info, err := os.Stat(filepath.Join(dir, path)); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+type readmeImage struct {
+	url  string
+	line int
+}
+
+// readmeImages returns the image URLs referenced by a Markdown file.
+func readmeImages(path string) ([]readmeImage, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var images []readmeImage
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		for _, pattern := range []*regexp.Regexp{markdownImagePattern, htmlImagePattern} {
+			for _, m := range pattern.FindAllStringSubmatch(text, -1) {
+				images = append(images, readmeImage{url: m[1], line: line})
+			}
+		}
+	}
+
+	return images, scanner.Err()
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const mitLicense = `MIT License
+
+Copyright (c) 2024 Example
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction.
+`
+
+func writePackageFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+}
+
+func TestDetectLicense(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"mit", mitLicense, "MIT"},
+		{"apache", "Apache License\n   Version 2.0, January 2004\n", "Apache-2.0"},
+		{"bsd3", "Redistribution and use in source and binary forms, with or without\nmodification... Neither the name of", "BSD-3-Clause"},
+		{"bsd2", "Redistribution and use in source and binary forms, with or without", "BSD-2-Clause"},
+		{"lgpl", "GNU LESSER GENERAL PUBLIC LICENSE Version 3", "LGPL"},
+		{"proprietary", "All rights reserved. Do not distribute.", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectLicense(tt.text); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCheckPackageFiles(t *testing.T) {
+	defaults := PackageFilesConfig{
+		Readme:       PolicyWarn,
+		ReadmeImages: PolicyWarn,
+		License:      PolicyFail,
+		Example:      PolicyWarn,
+	}
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		cfg   PackageFilesConfig
+		want  map[string]Severity
+	}{
+		{
+			name: "complete package",
+			files: map[string]string{
+				"README.md":         "# my_package\n\n![logo](https://example.dev/logo.png)\n",
+				"LICENSE":           mitLicense,
+				"example/main.dart": "void main() {}\n",
+			},
+			cfg:  defaults,
+			want: map[string]Severity{},
+		},
+		{
+			name:  "empty package",
+			files: map[string]string{},
+			cfg:   defaults,
+			want: map[string]Severity{
+				"readme-missing":  SeverityWarning,
+				"license-missing": SeverityError,
+				"example-missing": SeverityWarning,
+			},
+		},
+		{
+			name: "unrecognized license and named example",
+			files: map[string]string{
+				"README.md":                       "# my_package\n",
+				"LICENSE.md":                      "All rights reserved.",
+				"example/my_package_example.dart": "void main() {}\n",
+			},
+			cfg: defaults,
+			want: map[string]Severity{
+				"license-unrecognized": SeverityError,
+			},
+		},
+		{
+			name: "readme images",
+			files: map[string]string{
+				"README.md":         "# my_package\n\n![shot](doc/screen.png)\n<img src=\"http://example.dev/a.png\">\n",
+				"LICENSE":           mitLicense,
+				"example/README.md": "Usage",
+			},
+			cfg: PackageFilesConfig{
+				Readme:       PolicyWarn,
+				ReadmeImages: PolicyFail,
+				License:      PolicyFail,
+				Example:      PolicyWarn,
+			},
+			want: map[string]Severity{
+				"readme-image-relative": SeverityError,
+				"readme-image-insecure": SeverityError,
+			},
+		},
+		{
+			name:  "ignored checks",
+			files: map[string]string{},
+			cfg: PackageFilesConfig{
+				Readme:       PolicyIgnore,
+				ReadmeImages: PolicyIgnore,
+				License:      PolicyIgnore,
+				Example:      PolicyIgnore,
+			},
+			want: map[string]Severity{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writePackageFiles(t, dir, tt.files)
+
+			issues := CheckPackageFiles(dir, "my_package", tt.cfg)
+
+			got := make(map[string]Severity)
+			for _, issue := range issues {
+				got[issue.Rule] = issue.Severity
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, issues)
+			}
+			for rule, severity := range tt.want {
+				if got[rule] != severity {
+					t.Errorf("expected %s with severity %s, got %q", rule, severity, got[rule])
+				}
+			}
+		})
+	}
+}
+
+func TestCheckPackageFiles_ImageLocation(t *testing.T) {
+	dir := t.TempDir()
+	writePackageFiles(t, dir, map[string]string{
+		"README.md": "# my_package\n\nIntro\n\n![shot](screen.png)\n",
+	})
+
+	issues := CheckPackageFiles(dir, "my_package", PackageFilesConfig{
+		Readme:       PolicyWarn,
+		ReadmeImages: PolicyWarn,
+		License:      PolicyIgnore,
+		Example:      PolicyIgnore,
+	})
+	if len(issues) != 1 {
+		t.Fatalf("expected 1 issue, got %v", issues)
+	}
+
+	issue := issues[0]
+	if issue.configField() != "package_files.readme_images" {
+		t.Errorf("unexpected field %s", issue.configField())
+	}
+	want := "README.md:5:1: image screen.png uses a relative URL; use an absolute https URL so it renders on pub.dev"
+	if issue.String() != want {
+		t.Errorf("expected %q, got %q", want, issue.String())
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Pana section and check statuses.
+const (
+	PanaStatusPassed  = "passed"
+	PanaStatusPartial = "partial"
+	PanaStatusFailed  = "failed"
+)
+
+// panaCheckPattern matches check headings in a section summary, e.g.
+// "### [x] 0/10 points: Provide a documentation".
+var panaCheckPattern = regexp.MustCompile(`(?m)^###\s*\[([*~x])\]\s*(\d+)/(\d+) points:\s*(.+)$`)
+
+// PanaReport represents the machine-readable output of `pana --json`.
+type PanaReport struct {
+	PackageName    string     `json:"packageName"`
+	PackageVersion string     `json:"packageVersion"`
+	Report         PanaResult `json:"report"`
+}
+
+// PanaResult holds the scored sections of a pana report.
+type PanaResult struct {
+	Sections []PanaSection `json:"sections"`
+}
+
+// PanaSection is a single scored section of a pana report.
+type PanaSection struct {
+	ID            string `json:"id"`
+	Title         string `json:"title"`
+	GrantedPoints int    `json:"grantedPoints"`
+	MaxPoints     int    `json:"maxPoints"`
+	Status        string `json:"status"`
+	Summary       string `json:"summary"`
+}
+
+// PanaCheck is an individual check parsed from a section summary.
+type PanaCheck struct {
+	Section       string
+	Title         string
+	Status        string
+	GrantedPoints int
+	MaxPoints     int
+}
+
+// ParsePana parses the JSON output of `pana --json`.
+func ParsePana(data []byte) (*PanaReport, error) {
+	var report PanaReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse pana output: %w", err)
+	}
+	return &report, nil
+}
+
+// Score returns the total granted and maximum points.
+func (r *PanaReport) Score() (granted, maxPoints int) {
+	for _, s := range r.Report.Sections {
+		granted += s.GrantedPoints
+		maxPoints += s.MaxPoints
+	}
+	return granted, maxPoints
+}
+
+// Checks returns the individual checks from all section summaries.
+func (r *PanaReport) Checks() []PanaCheck {
+	var checks []PanaCheck
+	for _, s := range r.Report.Sections {
+		for _, m := range panaCheckPattern.FindAllStringSubmatch(s.Summary, -1) {
+			granted, _ := strconv.Atoi(m[2])
+			maxPoints, _ := strconv.Atoi(m[3])
+			checks = append(checks, PanaCheck{
+				Section:       s.ID,
+				Title:         strings.TrimSpace(m[4]),
+				Status:        panaMarkerStatus(m[1]),
+				GrantedPoints: granted,
+				MaxPoints:     maxPoints,
+			})
+		}
+	}
+	return checks
+}
+
+// EvaluatePana checks the report against the configured thresholds and
+// returns a description of every violation.
+func EvaluatePana(report *PanaReport, cfg PanaConfig) []string {
+	var failures []string
+
+	granted, maxPoints := report.Score()
+	if cfg.MinScore > 0 && granted < cfg.MinScore {
+		failures = append(failures, fmt.Sprintf("pub score %d/%d is below minimum %d", granted, maxPoints, cfg.MinScore))
+	}
+
+	for _, id := range cfg.FailOnSections {
+		for _, s := range report.Report.Sections {
+			if s.ID == id && s.Status != PanaStatusPassed {
+				failures = append(failures, fmt.Sprintf("section %q %s (%d/%d points)", s.ID, s.Status, s.GrantedPoints, s.MaxPoints))
+			}
+		}
+	}
+
+	checks := report.Checks()
+	for _, pattern := range cfg.FailOnChecks {
+		for _, c := range checks {
+			if c.Status != PanaStatusPassed && strings.Contains(strings.ToLower(c.Title), strings.ToLower(pattern)) {
+				failures = append(failures, fmt.Sprintf("check %q %s (%d/%d points)", c.Title, c.Status, c.GrantedPoints, c.MaxPoints))
+			}
+		}
+	}
+
+	return failures
+}
+
+// FormatPanaBreakdown renders the per-section score breakdown.
+func FormatPanaBreakdown(report *PanaReport) string {
+	var sb strings.Builder
+
+	granted, maxPoints := report.Score()
+	sb.WriteString(fmt.Sprintf("Pub score: %d/%d\n", granted, maxPoints))
+	for _, s := range report.Report.Sections {
+		sb.WriteString(fmt.Sprintf("  %3d/%-3d %s (%s)\n", s.GrantedPoints, s.MaxPoints, s.Title, s.Status))
+	}
+
+	return sb.String()
+}
+
+func panaMarkerStatus(marker string) string {
+	switch marker {
+	case "*":
+		return PanaStatusPassed
+	case "~":
+		return PanaStatusPartial
+	default:
+		return PanaStatusFailed
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const panaJSON = `{
+  "packageName": "my_package",
+  "packageVersion": "1.0.0",
+  "report": {
+    "sections": [
+      {
+        "id": "convention",
+        "title": "Follow Dart file conventions",
+        "grantedPoints": 20,
+        "maxPoints": 30,
+        "status": "partial",
+        "summary": "### [*] 10/10 points: Provide a valid ` + "`pubspec.yaml`" + `\n\n### [x] 0/5 points: Provide a valid ` + "`CHANGELOG.md`" + `\n\n### [~] 10/15 points: Use an OSI-approved license\n"
+      },
+      {
+        "id": "documentation",
+        "title": "Provide documentation",
+        "grantedPoints": 10,
+        "maxPoints": 20,
+        "status": "failed",
+        "summary": "### [*] 10/10 points: Package has an example\n\n### [x] 0/10 points: 20% or more of the public API has dartdoc comments\n"
+      },
+      {
+        "id": "analysis",
+        "title": "Pass static analysis",
+        "grantedPoints": 50,
+        "maxPoints": 50,
+        "status": "passed",
+        "summary": "### [*] 50/50 points: code has no errors, warnings, lints, or formatting issues\n"
+      }
+    ]
+  }
+}`
+
+func TestParsePana(t *testing.T) {
+	report, err := ParsePana([]byte(panaJSON))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if report.PackageName != "my_package" {
+		t.Errorf("expected package name my_package, got %s", report.PackageName)
+	}
+
+	granted, maxPoints := report.Score()
+	if granted != 80 || maxPoints != 100 {
+		t.Errorf("expected score 80/100, got %d/%d", granted, maxPoints)
+	}
+
+	checks := report.Checks()
+	if len(checks) != 6 {
+		t.Fatalf("expected 6 checks, got %d", len(checks))
+	}
+	if checks[1].Status != PanaStatusFailed || checks[1].MaxPoints != 5 {
+		t.Errorf("unexpected changelog check: %+v", checks[1])
+	}
+	if checks[2].Status != PanaStatusPartial || checks[2].GrantedPoints != 10 {
+		t.Errorf("unexpected license check: %+v", checks[2])
+	}
+
+	if _, err := ParsePana([]byte(`{invalid`)); err == nil {
+		t.Error("expected error for invalid json")
+	}
+}
+
+func TestEvaluatePana(t *testing.T) {
+	report, err := ParsePana([]byte(panaJSON))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		cfg       PanaConfig
+		wantCount int
+		contains  string
+	}{
+		{
+			name:      "no thresholds",
+			cfg:       PanaConfig{},
+			wantCount: 0,
+		},
+		{
+			name:      "score above minimum",
+			cfg:       PanaConfig{MinScore: 80},
+			wantCount: 0,
+		},
+		{
+			name:      "score below minimum",
+			cfg:       PanaConfig{MinScore: 90},
+			wantCount: 1,
+			contains:  "below minimum 90",
+		},
+		{
+			name:      "failed section",
+			cfg:       PanaConfig{FailOnSections: []string{"documentation", "analysis"}},
+			wantCount: 1,
+			contains:  `section "documentation" failed`,
+		},
+		{
+			name:      "failed check",
+			cfg:       PanaConfig{FailOnChecks: []string{"changelog", "example"}},
+			wantCount: 1,
+			contains:  "CHANGELOG.md",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failures := EvaluatePana(report, tt.cfg)
+			if len(failures) != tt.wantCount {
+				t.Fatalf("expected %d failures, got %d: %v", tt.wantCount, len(failures), failures)
+			}
+			if tt.contains != "" && !strings.Contains(failures[0], tt.contains) {
+				t.Errorf("expected failure to contain %q, got %q", tt.contains, failures[0])
+			}
+		})
+	}
+}
+
+func TestFormatPanaBreakdown(t *testing.T) {
+	report, err := ParsePana([]byte(panaJSON))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	breakdown := FormatPanaBreakdown(report)
+	if !strings.HasPrefix(breakdown, "Pub score: 80/100") {
+		t.Errorf("expected total score header, got:\n%s", breakdown)
+	}
+	if !strings.Contains(breakdown, "Provide documentation (failed)") {
+		t.Errorf("expected section line, got:\n%s", breakdown)
+	}
+}
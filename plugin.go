@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"log/slog"
 	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/relicta-tech/relicta-plugin-sdk/helpers"
 	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
@@ -13,22 +16,66 @@ import (
 // Version is set at build time.
 var Version = "0.1.0"
 
+// Check policies shared by configurable checks.
+const (
+	PolicyFail   = "fail"
+	PolicyWarn   = "warn"
+	PolicyIgnore = "ignore"
+)
+
+// DefaultRegistryURL is the registry used when neither publish_to nor hosted_url is set.
+const DefaultRegistryURL = "https://pub.dev"
+
 // Config represents Pub plugin configuration.
 type Config struct {
-	PubspecPath     string     `json:"pubspec_path"`
-	UpdateVersion   bool       `json:"update_version"`
-	CredentialsPath string     `json:"credentials_path"`
-	AccessToken     string     `json:"access_token"`
-	HostedURL       string     `json:"hosted_url"`
-	Validate        bool       `json:"validate"`
-	Analyze         bool       `json:"analyze"`
-	FormatCheck     bool       `json:"format_check"`
-	Test            bool       `json:"test"`
-	TestConfig      TestConfig `json:"test_config"`
-	DryRunValidate  bool       `json:"dry_run_validate"`
-	Force           bool       `json:"force"`
-	Exclude         []string   `json:"exclude"`
-	DryRun          bool       `json:"dry_run"`
+	PubspecPath     string             `json:"pubspec_path"`
+	UpdateVersion   bool               `json:"update_version"`
+	PubGet          bool               `json:"pub_get"`
+	PubGetConfig    PubGetConfig       `json:"pub_get_config"`
+	Outdated        bool               `json:"outdated"`
+	OutdatedConfig  OutdatedConfig     `json:"outdated_config"`
+	CheckDowngrade  bool               `json:"check_downgrade"`
+	DowngradeConfig DowngradeConfig    `json:"downgrade_config"`
+	Pana            bool               `json:"pana"`
+	PanaConfig      PanaConfig         `json:"pana_config"`
+	Changelog       bool               `json:"changelog"`
+	ChangelogConfig ChangelogConfig    `json:"changelog_config"`
+	PackageFiles    PackageFilesConfig `json:"package_files"`
+	CheckArchive    bool               `json:"check_archive"`
+	ArchiveConfig   ArchiveConfig      `json:"archive_config"`
+	ScanSecrets     bool               `json:"scan_secrets"`
+	CheckGit        bool               `json:"check_git"`
+	GitConfig       GitConfig          `json:"git_config"`
+	GitCommit       bool               `json:"git_commit"`
+	GitCommitConfig GitCommitConfig    `json:"git_commit_config"`
+	StepHooks       StepHooksConfig    `json:"step_hooks"`
+	Steps           []StepConfig       `json:"steps"`
+	Parallelism     int                `json:"parallelism"`
+	BeforePublish   []CommandConfig    `json:"before_publish"`
+	AfterPublish    []CommandConfig    `json:"after_publish"`
+	Timeouts        map[string]string  `json:"timeouts"`
+	Deadline        string             `json:"deadline"`
+	Retry           RetryConfig        `json:"retry"`
+	ReportPath      string             `json:"report_path"`
+	ReportMarkdown  bool               `json:"report_markdown"`
+	Rollback        bool               `json:"rollback"`
+	Retract         bool               `json:"retract"`
+	CredentialsPath string             `json:"credentials_path"`
+	AccessToken     string             `json:"access_token"`
+	HostedURL       string             `json:"hosted_url"`
+	Validate        bool               `json:"validate"`
+	Analyze         bool               `json:"analyze"`
+	FormatCheck     bool               `json:"format_check"`
+	Test            bool               `json:"test"`
+	TestConfig      TestConfig         `json:"test_config"`
+	DryRunValidate  bool               `json:"dry_run_validate"`
+	Force           bool               `json:"force"`
+	Exclude         []string           `json:"exclude"`
+	DryRun          bool               `json:"dry_run"`
+
+	// hostedURLFromEnv records that HostedURL came from PUB_HOSTED_URL
+	// rather than the plugin config.
+	hostedURLFromEnv bool
 }
 
 // TestConfig defines test execution options.
@@ -38,6 +85,77 @@ type TestConfig struct {
 	Coverage    bool   `json:"coverage"`
 }
 
+// PubGetConfig defines dependency resolution options.
+type PubGetConfig struct {
+	EnforceLockfile bool `json:"enforce_lockfile"`
+	Offline         bool `json:"offline"`
+}
+
+// OutdatedConfig defines the dependency health gate policies.
+// Each policy is one of "fail", "warn" or "ignore".
+type OutdatedConfig struct {
+	Discontinued      string `json:"discontinued"`
+	Retracted         string `json:"retracted"`
+	MajorBehind       string `json:"major_behind"`
+	IncludeDev        bool   `json:"include_dev"`
+	IncludeTransitive bool   `json:"include_transitive"`
+}
+
+// DowngradeConfig defines the lower-bound resolution check options.
+type DowngradeConfig struct {
+	Test bool `json:"test"`
+}
+
+// PanaConfig defines the pub score preview options.
+type PanaConfig struct {
+	Executable     string   `json:"executable"`
+	MinScore       int      `json:"min_score"`
+	FailOnSections []string `json:"fail_on_sections"`
+	FailOnChecks   []string `json:"fail_on_checks"`
+}
+
+// ChangelogConfig defines CHANGELOG.md verification options.
+type ChangelogConfig struct {
+	Generate bool `json:"generate"`
+}
+
+// PackageFilesConfig defines the policy for each package file check.
+// Each policy is one of "fail", "warn" or "ignore".
+type PackageFilesConfig struct {
+	Readme       string `json:"readme"`
+	ReadmeImages string `json:"readme_images"`
+	License      string `json:"license"`
+	Example      string `json:"example"`
+}
+
+// ArchiveConfig defines the package archive inspection limits.
+// Sizes are in bytes; zero disables a limit.
+type ArchiveConfig struct {
+	MaxSize           int64    `json:"max_size"`
+	MaxFileSize       int64    `json:"max_file_size"`
+	ForbiddenPatterns []string `json:"forbidden_patterns"`
+	Largest           int      `json:"largest"`
+}
+
+// GitConfig defines the git repository consistency checks.
+type GitConfig struct {
+	Remote     string `json:"remote"`
+	Clean      bool   `json:"clean"`
+	Commit     bool   `json:"commit"`
+	Repository bool   `json:"repository"`
+}
+
+// GitCommitConfig defines how release file changes are committed.
+// Message is a text/template rendered with TemplateData.
+type GitCommitConfig struct {
+	Message     string `json:"message"`
+	AuthorName  string `json:"author_name"`
+	AuthorEmail string `json:"author_email"`
+}
+
+// DefaultCommitMessage is the default template for release commits.
+const DefaultCommitMessage = "chore(release): {{.Package}} {{.Version}}"
+
 // PubPlugin implements the Dart/Flutter Pub plugin.
 type PubPlugin struct{}
 
@@ -48,8 +166,12 @@ func (p *PubPlugin) GetInfo() plugin.Info {
 		Version:     Version,
 		Description: "Dart/Flutter package publishing to pub.dev",
 		Hooks: []plugin.Hook{
+			plugin.HookPostVersion,
+			plugin.HookPostNotes,
 			plugin.HookPrePublish,
 			plugin.HookPostPublish,
+			plugin.HookOnSuccess,
+			plugin.HookOnError,
 		},
 	}
 }
@@ -58,12 +180,81 @@ func (p *PubPlugin) GetInfo() plugin.Info {
 func (p *PubPlugin) Validate(ctx context.Context, config map[string]any) (*plugin.ValidateResponse, error) {
 	cfg := p.parseConfig(config)
 	vb := helpers.NewValidationBuilder()
+	var issues []ValidationIssue
 
 	// Check Dart installation
 	if _, err := exec.LookPath("dart"); err != nil {
 		vb.AddError("dart", "Dart SDK not found in PATH")
 	}
 
+	// Check policies
+	policies := []struct {
+		field string
+		value string
+	}{
+		{"outdated_config.discontinued", cfg.OutdatedConfig.Discontinued},
+		{"outdated_config.retracted", cfg.OutdatedConfig.Retracted},
+		{"outdated_config.major_behind", cfg.OutdatedConfig.MajorBehind},
+		{"package_files.readme", cfg.PackageFiles.Readme},
+		{"package_files.readme_images", cfg.PackageFiles.ReadmeImages},
+		{"package_files.license", cfg.PackageFiles.License},
+		{"package_files.example", cfg.PackageFiles.Example},
+	}
+	for _, policy := range policies {
+		switch policy.value {
+		case PolicyFail, PolicyWarn, PolicyIgnore:
+		default:
+			vb.AddError(policy.field, fmt.Sprintf("%s must be one of: fail, warn, ignore", policy.field))
+		}
+	}
+
+	// Check archive limits
+	if cfg.ArchiveConfig.Largest < 0 {
+		vb.AddError("archive_config.largest", "archive_config.largest must not be negative")
+	}
+
+	// Check step hook assignments
+	validateStepHooks(vb, cfg)
+
+	// Check pipeline steps
+	validateSteps(vb, cfg.Steps)
+	if cfg.Parallelism < 1 {
+		vb.AddError("parallelism", "parallelism must be at least 1")
+	}
+
+	// Check publish commands
+	validateCommands(vb, "before_publish", cfg.BeforePublish)
+	validateCommands(vb, "after_publish", cfg.AfterPublish)
+
+	// Check timeouts
+	validateTimeouts(vb, cfg)
+
+	// Check publish retry policy
+	if cfg.Retry.MaxAttempts < 1 {
+		vb.AddError("retry.max_attempts", "retry.max_attempts must be at least 1")
+	}
+	if !validDuration(cfg.Retry.InitialDelay) {
+		vb.AddError("retry.initial_delay", fmt.Sprintf("retry.initial_delay: invalid duration %q", cfg.Retry.InitialDelay))
+	}
+	if !validDuration(cfg.Retry.MaxDelay) {
+		vb.AddError("retry.max_delay", fmt.Sprintf("retry.max_delay: invalid duration %q", cfg.Retry.MaxDelay))
+	}
+
+	// Check release report
+	if cfg.ReportMarkdown && cfg.ReportPath == "" {
+		vb.AddError("report_markdown", "report_markdown requires report_path")
+	}
+	if strings.EqualFold(filepath.Ext(cfg.ReportPath), ".md") {
+		vb.AddError("report_path", "report_path is the JSON report; the Markdown report is written next to it")
+	}
+
+	// Check commit message template
+	if cfg.GitCommit {
+		if _, err := RenderTemplate(cfg.GitCommitConfig.Message, TemplateData{}); err != nil {
+			vb.AddError("git_commit_config.message", err.Error())
+		}
+	}
+
 	// Check pubspec.yaml
 	pubspecPath := cfg.PubspecPath
 	if pubspecPath == "" {
@@ -74,16 +265,20 @@ func (p *PubPlugin) Validate(ctx context.Context, config map[string]any) (*plugi
 	if err != nil {
 		vb.AddError("pubspec_path", fmt.Sprintf("Invalid pubspec.yaml: %v", err))
 	} else {
-		if err := ValidatePubspec(pubspec); err != nil {
-			// Use AddError for pubspec validation issues as they are important
-			vb.AddError("pubspec", err.Error())
+		issues = ValidatePubspec(pubspec)
+		issues = append(issues, checkRegistryConflict(pubspec, cfg)...)
+		issues = append(issues, checkDependencyHosts(pubspec, resolveRegistry(pubspec, cfg))...)
+		issues = append(issues, checkLocalSDKs(ctx, pubspec, filepath.Dir(pubspecPath))...)
+		if pubspec.IsPublishable() {
+			issues = append(issues, CheckPackageFiles(filepath.Dir(pubspecPath), pubspec.Name, cfg.PackageFiles)...)
 		}
 	}
 
 	// Credentials are optional in validation - just check if they exist
 	// The actual authentication will be validated at runtime
 
-	return vb.Build(), nil
+	logger := slog.Default().With("plugin", "pub", "hook", "validate")
+	return buildValidateResponse(vb, issues, logger), nil
 }
 
 // Execute runs the plugin for a given hook.
@@ -93,10 +288,22 @@ func (p *PubPlugin) Execute(ctx context.Context, req plugin.ExecuteRequest) (*pl
 	logger := slog.Default().With("plugin", "pub", "hook", req.Hook)
 
 	switch req.Hook {
+	case plugin.HookPostVersion, plugin.HookPostNotes:
+		resp, err := p.executeFileSteps(ctx, req.Hook, &req.Context, cfg, logger)
+		return p.rollbackOnFailure(ctx, cfg, logger, resp, err)
 	case plugin.HookPrePublish:
-		return p.executePrePublish(ctx, &req.Context, cfg, logger)
+		report := newHookReport(req.Hook, cfg.DryRun)
+		resp, err := p.executePrePublish(ctx, &req.Context, cfg, report.commands, logger)
+		resp, err = p.rollbackOnFailure(ctx, cfg, logger, resp, err)
+		return p.saveReport(ctx, &req.Context, cfg, report, logger, resp, err)
 	case plugin.HookPostPublish:
-		return p.executePostPublish(ctx, &req.Context, cfg, logger)
+		report := newHookReport(req.Hook, cfg.DryRun)
+		resp, err := p.executePostPublish(ctx, &req.Context, cfg, report.commands, logger)
+		return p.saveReport(ctx, &req.Context, cfg, report, logger, resp, err)
+	case plugin.HookOnSuccess:
+		return p.executeOnSuccess(&req.Context, cfg, logger)
+	case plugin.HookOnError:
+		return p.executeOnError(ctx, &req.Context, cfg, logger)
 	default:
 		return &plugin.ExecuteResponse{
 			Success: true,
@@ -105,7 +312,40 @@ func (p *PubPlugin) Execute(ctx context.Context, req plugin.ExecuteRequest) (*pl
 	}
 }
 
-func (p *PubPlugin) executePrePublish(ctx context.Context, releaseCtx *plugin.ReleaseContext, cfg *Config, logger *slog.Logger) (*plugin.ExecuteResponse, error) {
+func (p *PubPlugin) executePrePublish(ctx context.Context, releaseCtx *plugin.ReleaseContext, cfg *Config, commands *CommandLog, logger *slog.Logger) (*plugin.ExecuteResponse, error) {
+	r, err := newReleaseRun(releaseCtx, cfg, logger)
+	if err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to prepare release: %v", err),
+		}, nil
+	}
+	r.dart.SetCommandLog(commands)
+
+	// Forget versions published by a previous run
+	if !cfg.DryRun {
+		if err := RemovePublishRecord(r.packageDir); err != nil {
+			r.logger.Warn("Failed to remove stale publish record", "error", err)
+		}
+	}
+
+	results, fatal := r.runPipeline(ctx, plugin.HookPrePublish)
+	if fatal == nil {
+		// The report records the checksum of the validated archive
+		if cfg.ReportPath != "" && r.pubspec.IsPublishable() {
+			if _, err := r.archiveReport(); err != nil {
+				r.logger.Warn("Failed to build package archive for the report", "error", err)
+			}
+		}
+		r.logger.Info("PrePublish completed successfully")
+	}
+	return r.pipelineResponse(plugin.HookPrePublish, results, fatal), nil
+}
+
+func (p *PubPlugin) executePostPublish(ctx context.Context, releaseCtx *plugin.ReleaseContext, cfg *Config, commands *CommandLog, logger *slog.Logger) (*plugin.ExecuteResponse, error) {
+	ctx, cancel := withDeadline(ctx, cfg)
+	defer cancel()
+
 	version := releaseCtx.Version
 	logger = logger.With("version", version)
 
@@ -125,169 +365,406 @@ func (p *PubPlugin) executePrePublish(ctx context.Context, releaseCtx *plugin.Re
 
 	logger = logger.With("package", pubspec.Name)
 
-	// Determine if this is a Flutter package
-	isFlutter := IsFlutterPackage(pubspec)
-	if isFlutter {
-		logger = logger.With("flutter", true)
+	if !pubspec.IsPublishable() {
+		logger.Info("Skipping publish: publish_to is none")
+		return &plugin.ExecuteResponse{
+			Success: true,
+			Message: fmt.Sprintf("Skipped publishing %s@%s: pubspec.yaml declares publish_to: none", pubspec.Name, version),
+			Outputs: map[string]any{
+				"package":   pubspec.Name,
+				"version":   version,
+				"published": false,
+			},
+		}, nil
 	}
 
-	dart := NewDartCLI(".")
+	registry := resolveRegistry(pubspec, cfg)
+	logger = logger.With("registry", registry)
 
-	// Update version in pubspec.yaml
-	if cfg.UpdateVersion {
-		logger.Info("Updating version in pubspec.yaml")
-		if cfg.DryRun {
-			logger.Info("[DRY-RUN] Would update version", "from", pubspec.Version, "to", version)
-		} else {
-			if err := UpdateVersion(pubspecPath, version); err != nil {
-				return &plugin.ExecuteResponse{
-					Success: false,
-					Message: fmt.Sprintf("Failed to update version: %v", err),
-				}, nil
+	creds := loadCredentials(cfg)
+	dart := NewDartCLI(filepath.Dir(pubspecPath))
+	dart.SetCredentials(creds)
+	dart.SetCommandLog(commands)
+
+	// Set hosted URL for custom registries
+	if registry != DefaultRegistryURL {
+		dart.SetHostedURL(registry)
+	}
+
+	outputs := map[string]any{
+		"package":   pubspec.Name,
+		"version":   version,
+		"registry":  registry,
+		"published": false,
+		"dry_run":   cfg.DryRun,
+	}
+
+	data := TemplateData{
+		Package:         pubspec.Name,
+		Version:         version,
+		PreviousVersion: releaseCtx.PreviousVersion,
+		Tag:             releaseCtx.TagName,
+	}
+
+	// Run commands such as code generation before publishing
+	notes, err := RunCommands(ctx, dart, "before_publish", cfg.BeforePublish, data, cfg.DryRun, logger)
+	findings := noteFindings("before_publish", notes)
+	if len(findings) > 0 {
+		outputs["findings"] = findings
+	}
+	if err != nil {
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Message: fmt.Sprintf("Before-publish command failed: %v", err),
+			Outputs: outputs,
+		}, nil
+	}
+
+	// Publish
+	logger.Info("Publishing package")
+	if cfg.DryRun {
+		logger.Info("[DRY-RUN] Would publish package",
+			"package", pubspec.Name,
+			"version", version,
+			"force", cfg.Force)
+	} else {
+		attempts, err := RetryPublish(ctx, cfg.Retry, logger, func(ctx context.Context) error {
+			return publish(ctx, dart, cfg)
+		})
+		outputs["attempts"] = attemptOutputs(attempts)
+		if err != nil {
+			msg := fmt.Sprintf("Publish failed: %v", err)
+			if len(attempts) > 1 {
+				msg = fmt.Sprintf("Publish failed after %d attempts: %v\n\n%s", len(attempts), err, FormatPublishAttempts(attempts))
 			}
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Message: msg,
+				Outputs: outputs,
+			}, nil
+		}
+		outputs["published"] = true
+		if len(attempts) > 1 {
+			notes = append(notes, fmt.Sprintf("Published after %d attempts:\n%s",
+				len(attempts), strings.TrimSuffix(FormatPublishAttempts(attempts), "\n")))
 		}
 	}
 
-	// Run dart analyze
-	if cfg.Analyze {
-		logger.Info("Running dart analyze")
-		if cfg.DryRun {
-			logger.Info("[DRY-RUN] Would run dart analyze")
+	// Tell downstream plugins where to find the version
+	if !cfg.DryRun {
+		if pageURL := publishedPageURL(registry, pubspec.Name, version); pageURL != "" {
+			outputs["package_url"] = pageURL
+		}
+		var token string
+		if creds != nil {
+			token = creds.AccessToken
+		}
+		archive, err := NewPubRegistryClient(registry, token).VersionArchive(ctx, pubspec.Name, version)
+		if err != nil {
+			logger.Warn("Failed to look up archive URL", "error", err)
 		} else {
-			if err := dart.Analyze(ctx); err != nil {
-				return &plugin.ExecuteResponse{
-					Success: false,
-					Message: fmt.Sprintf("Analysis failed: %v", err),
-				}, nil
+			outputs["archive_url"] = archive.URL
+			if archive.SHA256 != "" {
+				outputs["archive_sha256"] = archive.SHA256
 			}
 		}
 	}
 
-	// Run format check
-	if cfg.FormatCheck {
-		logger.Info("Checking code formatting")
-		if cfg.DryRun {
-			logger.Info("[DRY-RUN] Would check code formatting")
-		} else {
-			if err := dart.FormatCheck(ctx); err != nil {
-				return &plugin.ExecuteResponse{
-					Success: false,
-					Message: fmt.Sprintf("Format check failed: %v", err),
-				}, nil
-			}
+	// The version is out; a later failure must not revert the bump
+	if !cfg.DryRun {
+		snapshot, err := LoadSnapshot(filepath.Dir(pubspecPath))
+		if err == nil {
+			err = snapshot.Discard()
+		}
+		if err != nil {
+			logger.Warn("Failed to discard rollback snapshot", "error", err)
 		}
 	}
 
-	// Run tests
-	if cfg.Test {
-		logger.Info("Running tests")
-		if cfg.DryRun {
-			logger.Info("[DRY-RUN] Would run tests", "config", cfg.TestConfig, "flutter", isFlutter)
-		} else {
-			if isFlutter {
-				if err := dart.FlutterTest(ctx); err != nil {
-					return &plugin.ExecuteResponse{
-						Success: false,
-						Message: fmt.Sprintf("Flutter tests failed: %v", err),
-					}, nil
-				}
-			} else {
-				if err := dart.Test(ctx, cfg.TestConfig); err != nil {
-					return &plugin.ExecuteResponse{
-						Success: false,
-						Message: fmt.Sprintf("Tests failed: %v", err),
-					}, nil
-				}
-			}
+	// Record the published version so a later failure can retract it
+	if cfg.Retract && !cfg.DryRun {
+		err := SavePublishRecord(filepath.Dir(pubspecPath), &PublishRecord{
+			Package:     pubspec.Name,
+			Version:     version,
+			Registry:    registry,
+			PublishedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			logger.Warn("Failed to record published version", "error", err)
 		}
 	}
 
-	// Run dry-run validation
-	if cfg.DryRunValidate {
-		logger.Info("Running publish dry-run validation")
-		if cfg.DryRun {
-			logger.Info("[DRY-RUN] Would run dart pub publish --dry-run")
-		} else {
-			if err := dart.PublishDryRun(ctx); err != nil {
+	var msg string
+	if cfg.DryRun {
+		msg = fmt.Sprintf("[DRY-RUN] Would publish %s@%s to %s", pubspec.Name, version, registryName(registry))
+	} else {
+		msg = fmt.Sprintf("Published %s@%s to %s", pubspec.Name, version, registryName(registry))
+	}
+
+	// Run follow-up commands once the version is out. Failing the hook now
+	// would retract a correctly published version, so failures are warnings.
+	warnings, err := RunCommands(ctx, dart, "after_publish", cfg.AfterPublish, data, cfg.DryRun, logger)
+	if err != nil {
+		logger.Warn("After-publish command failed", "error", err)
+		warnings = append(warnings, fmt.Sprintf("warning: after-publish command failed, remaining commands skipped: %v", err))
+	}
+	notes = append(notes, warnings...)
+	findings = append(findings, noteFindings("after_publish", warnings)...)
+	if len(findings) > 0 {
+		outputs["findings"] = findings
+	}
+	if len(notes) > 0 {
+		msg = fmt.Sprintf("%s\n\n%s", msg, strings.Join(notes, "\n"))
+	}
+
+	logger.Info("PostPublish completed successfully")
+	return &plugin.ExecuteResponse{
+		Success: true,
+		Message: msg,
+		Outputs: outputs,
+	}, nil
+}
+
+// publish runs dart pub publish within the publish timeout. A publish that
+// runs out of time reports the output it produced so far.
+func publish(ctx context.Context, dart *DartCLI, cfg *Config) error {
+	timeout := cfg.timeoutFor(PublishTimeoutKey)
+	ctx, cancel := withTimeout(ctx, timeout, fmt.Errorf("timed out after %s", timeout))
+	defer cancel()
+
+	var output syncBuffer
+	publishDart := *dart
+	publishDart.SetOutput(&output)
+
+	err := publishDart.Publish(ctx, cfg.Force)
+	if cause := timeoutCause(ctx, err); cause != err {
+		err = cause
+		if out := tailLines(output.String(), stepOutputLines); out != "" {
+			err = fmt.Errorf("%w\n\nOutput:\n%s", err, out)
+		}
+	}
+	return err
+}
+
+// executeOnError retracts a version published by the failed release and
+// restores the files the plugin changed.
+func (p *PubPlugin) executeOnError(ctx context.Context, releaseCtx *plugin.ReleaseContext, cfg *Config, logger *slog.Logger) (*plugin.ExecuteResponse, error) {
+	pubspecPath := cfg.PubspecPath
+	if pubspecPath == "" {
+		pubspecPath = "pubspec.yaml"
+	}
+	packageDir := filepath.Dir(pubspecPath)
+
+	var msgs []string
+
+	// Retract the version if this release already published it
+	if cfg.Retract {
+		msg, err := retractPublished(ctx, releaseCtx, cfg, packageDir, logger)
+		if err != nil {
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Message: fmt.Sprintf("Retract failed: %v", err),
+			}, nil
+		}
+		if msg != "" {
+			msgs = append(msgs, msg)
+		}
+	}
+
+	// Restore the files changed in PrePublish
+	if cfg.Rollback {
+		snapshot, err := LoadSnapshot(packageDir)
+		if err != nil {
+			return &plugin.ExecuteResponse{
+				Success: false,
+				Message: fmt.Sprintf("Rollback failed: %v", err),
+			}, nil
+		}
+
+		if snapshot != nil && cfg.DryRun {
+			logger.Info("[DRY-RUN] Would roll back release changes", "files", len(snapshot.Files), "commit", snapshot.Commit)
+			msgs = append(msgs, fmt.Sprintf("[DRY-RUN] Would roll back %d file(s)", len(snapshot.Files)))
+		} else if snapshot != nil {
+			restored, err := rollback(ctx, pubspecPath, logger)
+			if err != nil {
 				return &plugin.ExecuteResponse{
 					Success: false,
-					Message: fmt.Sprintf("Dry-run validation failed: %v", err),
+					Message: fmt.Sprintf("Rollback failed: %v", err),
 				}, nil
 			}
+			msgs = append(msgs, fmt.Sprintf("Rolled back: %s", strings.Join(restored, ", ")))
 		}
 	}
 
-	logger.Info("PrePublish completed successfully")
+	if len(msgs) == 0 {
+		msgs = append(msgs, "Nothing to roll back")
+	}
+
 	return &plugin.ExecuteResponse{
 		Success: true,
-		Message: "Package validated successfully",
+		Message: strings.Join(msgs, "\n"),
 	}, nil
 }
 
-func (p *PubPlugin) executePostPublish(ctx context.Context, releaseCtx *plugin.ReleaseContext, cfg *Config, logger *slog.Logger) (*plugin.ExecuteResponse, error) {
-	version := releaseCtx.Version
-	logger = logger.With("version", version)
+// retractPublished retracts the version recorded as published by this
+// release, if any, and describes the outcome.
+func retractPublished(ctx context.Context, releaseCtx *plugin.ReleaseContext, cfg *Config, dir string, logger *slog.Logger) (string, error) {
+	record, err := LoadPublishRecord(dir)
+	if err != nil {
+		return "", err
+	}
+	if record == nil || (releaseCtx.Version != "" && record.Version != releaseCtx.Version) {
+		return "", nil
+	}
+	if !record.CanRetract(time.Now()) {
+		return "", fmt.Errorf("%s@%s was published more than 7 days ago", record.Package, record.Version)
+	}
 
-	pubspecPath := cfg.PubspecPath
-	if pubspecPath == "" {
-		pubspecPath = "pubspec.yaml"
+	logger.Info("Retracting published version", "package", record.Package, "version", record.Version, "registry", record.Registry)
+	if cfg.DryRun {
+		logger.Info("[DRY-RUN] Would retract version", "package", record.Package, "version", record.Version)
+		return fmt.Sprintf("[DRY-RUN] Would retract %s@%s from %s", record.Package, record.Version, registryName(record.Registry)), nil
 	}
 
-	// Parse pubspec to get package info
-	pubspec, err := ParsePubspec(pubspecPath)
-	if err != nil {
-		return &plugin.ExecuteResponse{
-			Success: false,
-			Message: fmt.Sprintf("Failed to parse pubspec.yaml: %v", err),
-		}, nil
+	creds := loadCredentials(cfg)
+	if creds == nil || creds.AccessToken == "" {
+		return "", fmt.Errorf("no access token available to retract %s@%s", record.Package, record.Version)
 	}
 
-	logger = logger.With("package", pubspec.Name)
+	client := NewPubRegistryClient(record.Registry, creds.AccessToken)
+	if err := client.Retract(ctx, record.Package, record.Version); err != nil {
+		return "", err
+	}
+	if err := RemovePublishRecord(dir); err != nil {
+		logger.Warn("Failed to remove publish record", "error", err)
+	}
 
-	// Setup credentials
-	var creds *PubCredentials
+	return fmt.Sprintf("Retracted %s@%s from %s", record.Package, record.Version, registryName(record.Registry)), nil
+}
+
+// loadCredentials returns the configured access token, falling back to the
+// pub credentials file.
+func loadCredentials(cfg *Config) *PubCredentials {
 	if cfg.AccessToken != "" {
-		creds = CreateCredentialsFromToken(cfg.AccessToken)
-	} else {
-		creds, _ = LoadCredentials(cfg.CredentialsPath)
+		return CreateCredentialsFromToken(cfg.AccessToken)
+	}
+	creds, _ := LoadCredentials(cfg.CredentialsPath)
+	return creds
+}
+
+// rollbackOnFailure restores the snapshotted files when a hook that changes
+// them fails, and notes the outcome in the response.
+func (p *PubPlugin) rollbackOnFailure(ctx context.Context, cfg *Config, logger *slog.Logger, resp *plugin.ExecuteResponse, err error) (*plugin.ExecuteResponse, error) {
+	if err != nil || resp.Success || !cfg.Rollback || cfg.DryRun {
+		return resp, err
 	}
 
-	dart := NewDartCLI(".")
-	dart.SetCredentials(creds)
+	restored, rollbackErr := rollback(ctx, cfg.PubspecPath, logger)
+	if rollbackErr != nil {
+		logger.Error("Rollback failed", "error", rollbackErr)
+		resp.Message = fmt.Sprintf("%s\n\nRollback failed: %v", resp.Message, rollbackErr)
+	} else if len(restored) > 0 {
+		resp.Message = fmt.Sprintf("%s\n\nRolled back: %s", resp.Message, strings.Join(restored, ", "))
+		if resp.Outputs == nil {
+			resp.Outputs = make(map[string]any)
+		}
+		resp.Outputs["rolled_back"] = restored
+	}
+	return resp, nil
+}
+
+// rollback restores the files snapshotted during PrePublish and returns
+// the reverted changes.
+func rollback(ctx context.Context, pubspecPath string, logger *slog.Logger) ([]string, error) {
+	logger.Info("Rolling back release changes")
+	snapshot, err := LoadSnapshot(filepath.Dir(pubspecPath))
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.Restore(ctx)
+}
+
+// pluginModifiedFiles returns the package-relative files the plugin may
+// rewrite during a release with the given config.
+func pluginModifiedFiles(pubspecPath string, cfg *Config) []string {
+	var files []string
+	if cfg.UpdateVersion {
+		files = append(files, filepath.Base(pubspecPath))
+	}
+	if cfg.PubGet {
+		files = append(files, "pubspec.lock")
+	}
+	if cfg.Changelog && cfg.ChangelogConfig.Generate {
+		files = append(files, ChangelogFile)
+	}
+	return files
+}
 
-	// Set hosted URL if configured
+// resolveRegistry returns the registry URL to publish to. A publish_to URL in
+// pubspec.yaml takes precedence over the configured hosted_url.
+func resolveRegistry(pubspec *Pubspec, cfg *Config) string {
+	if registry := pubspec.PublishRegistry(); registry != "" {
+		return registry
+	}
 	if cfg.HostedURL != "" {
-		dart.SetHostedURL(cfg.HostedURL)
+		return cfg.HostedURL
 	}
+	return DefaultRegistryURL
+}
 
-	// Publish
-	logger.Info("Publishing to pub.dev")
-	if cfg.DryRun {
-		logger.Info("[DRY-RUN] Would publish package",
-			"package", pubspec.Name,
-			"version", version,
-			"force", cfg.Force)
-	} else {
-		if err := dart.Publish(ctx, cfg.Force); err != nil {
-			return &plugin.ExecuteResponse{
-				Success: false,
-				Message: fmt.Sprintf("Publish failed: %v", err),
-			}, nil
-		}
+// registryName returns a short display name for a registry URL.
+func registryName(registry string) string {
+	if registry == DefaultRegistryURL {
+		return "pub.dev"
 	}
+	return registry
+}
 
-	var msg string
-	if cfg.DryRun {
-		msg = fmt.Sprintf("[DRY-RUN] Would publish %s@%s to pub.dev", pubspec.Name, version)
-	} else {
-		msg = fmt.Sprintf("Published %s@%s to pub.dev", pubspec.Name, version)
+// checkRegistryConflict reports disagreements between publish_to and hosted_url.
+// A PUB_HOSTED_URL mirror from the environment only warns, since publish_to
+// takes precedence over it.
+func checkRegistryConflict(pubspec *Pubspec, cfg *Config) []ValidationIssue {
+	if cfg.HostedURL == "" || pubspec.PublishTo == "" {
+		return nil
 	}
 
-	logger.Info("PostPublish completed successfully")
-	return &plugin.ExecuteResponse{
-		Success: true,
-		Message: msg,
-	}, nil
+	l := &issueList{pubspec: pubspec}
+	switch {
+	case !pubspec.IsPublishable():
+		l.warnf("publish_to", "publish-to-conflict",
+			"hosted_url %s is ignored because pubspec.yaml declares publish_to: none", cfg.HostedURL)
+	case strings.TrimRight(pubspec.PublishTo, "/") == strings.TrimRight(cfg.HostedURL, "/"):
+	case cfg.hostedURLFromEnv:
+		l.warnf("publish_to", "publish-to-conflict",
+			"PUB_HOSTED_URL %s is ignored because pubspec.yaml declares publish_to %s", cfg.HostedURL, pubspec.PublishTo)
+	default:
+		l.errorf("publish_to", "publish-to-conflict",
+			"publish_to %s conflicts with hosted_url %s", pubspec.PublishTo, cfg.HostedURL)
+	}
+	return l.issues
+}
+
+// checkLocalSDKs compares the installed Dart and Flutter SDKs with the
+// environment constraints. SDKs that are not installed are skipped here.
+func checkLocalSDKs(ctx context.Context, pubspec *Pubspec, dir string) []ValidationIssue {
+	dartVersion, flutterVersion := localSDKVersions(ctx, pubspec, dir)
+	return CheckSDKCompatibility(pubspec, dartVersion, flutterVersion)
+}
+
+// localSDKVersions returns the --version output of the installed Dart SDK
+// and, for Flutter packages, the Flutter SDK. Missing SDKs are empty.
+func localSDKVersions(ctx context.Context, pubspec *Pubspec, dir string) (dartVersion, flutterVersion string) {
+	dart := NewDartCLI(dir)
+
+	if _, err := exec.LookPath("dart"); err == nil {
+		dartVersion, _ = dart.GetVersion(ctx)
+	}
+	if IsFlutterPackage(pubspec) {
+		if _, err := exec.LookPath("flutter"); err == nil {
+			flutterVersion, _ = dart.GetFlutterVersion(ctx)
+		}
+	}
+	return dartVersion, flutterVersion
 }
 
 func (p *PubPlugin) parseConfig(raw map[string]any) *Config {
@@ -311,6 +788,150 @@ func (p *PubPlugin) parseConfig(raw map[string]any) *Config {
 		}
 	}
 
+	// Parse pub get config
+	var pubGetConfig PubGetConfig
+	if pubGetRaw, ok := raw["pub_get_config"].(map[string]any); ok {
+		if enforce, ok := pubGetRaw["enforce_lockfile"].(bool); ok {
+			pubGetConfig.EnforceLockfile = enforce
+		}
+		if offline, ok := pubGetRaw["offline"].(bool); ok {
+			pubGetConfig.Offline = offline
+		}
+	}
+
+	// Parse outdated config
+	outdatedConfig := OutdatedConfig{
+		Discontinued: PolicyFail,
+		Retracted:    PolicyFail,
+		MajorBehind:  PolicyWarn,
+	}
+	if outdatedRaw, ok := raw["outdated_config"].(map[string]any); ok {
+		if policy, ok := outdatedRaw["discontinued"].(string); ok {
+			outdatedConfig.Discontinued = policy
+		}
+		if policy, ok := outdatedRaw["retracted"].(string); ok {
+			outdatedConfig.Retracted = policy
+		}
+		if policy, ok := outdatedRaw["major_behind"].(string); ok {
+			outdatedConfig.MajorBehind = policy
+		}
+		if dev, ok := outdatedRaw["include_dev"].(bool); ok {
+			outdatedConfig.IncludeDev = dev
+		}
+		if transitive, ok := outdatedRaw["include_transitive"].(bool); ok {
+			outdatedConfig.IncludeTransitive = transitive
+		}
+	}
+
+	// Parse downgrade config
+	var downgradeConfig DowngradeConfig
+	if downgradeRaw, ok := raw["downgrade_config"].(map[string]any); ok {
+		if test, ok := downgradeRaw["test"].(bool); ok {
+			downgradeConfig.Test = test
+		}
+	}
+
+	// Parse pana config
+	panaConfig := PanaConfig{
+		Executable: "pana",
+	}
+	if panaRaw, ok := raw["pana_config"].(map[string]any); ok {
+		panaParser := helpers.NewConfigParser(panaRaw)
+		panaConfig.Executable = panaParser.GetString("executable", "", panaConfig.Executable)
+		panaConfig.MinScore = panaParser.GetInt("min_score", 0)
+		panaConfig.FailOnSections = panaParser.GetStringSlice("fail_on_sections", nil)
+		panaConfig.FailOnChecks = panaParser.GetStringSlice("fail_on_checks", nil)
+	}
+
+	// Parse changelog config
+	var changelogConfig ChangelogConfig
+	if changelogRaw, ok := raw["changelog_config"].(map[string]any); ok {
+		if generate, ok := changelogRaw["generate"].(bool); ok {
+			changelogConfig.Generate = generate
+		}
+	}
+
+	// Parse package file policies
+	packageFiles := PackageFilesConfig{
+		Readme:       PolicyWarn,
+		ReadmeImages: PolicyWarn,
+		License:      PolicyFail,
+		Example:      PolicyWarn,
+	}
+	if filesRaw, ok := raw["package_files"].(map[string]any); ok {
+		filesParser := helpers.NewConfigParser(filesRaw)
+		packageFiles.Readme = filesParser.GetString("readme", "", packageFiles.Readme)
+		packageFiles.ReadmeImages = filesParser.GetString("readme_images", "", packageFiles.ReadmeImages)
+		packageFiles.License = filesParser.GetString("license", "", packageFiles.License)
+		packageFiles.Example = filesParser.GetString("example", "", packageFiles.Example)
+	}
+
+	// Parse archive config
+	archiveConfig := ArchiveConfig{
+		MaxSize:           DefaultMaxArchiveSize,
+		ForbiddenPatterns: DefaultForbiddenPatterns,
+		Largest:           5,
+	}
+	if archiveRaw, ok := raw["archive_config"].(map[string]any); ok {
+		archiveParser := helpers.NewConfigParser(archiveRaw)
+		archiveConfig.MaxSize = int64(archiveParser.GetInt("max_size", int(archiveConfig.MaxSize)))
+		archiveConfig.MaxFileSize = int64(archiveParser.GetInt("max_file_size", 0))
+		archiveConfig.ForbiddenPatterns = archiveParser.GetStringSlice("forbidden_patterns", archiveConfig.ForbiddenPatterns)
+		archiveConfig.Largest = archiveParser.GetInt("largest", archiveConfig.Largest)
+	}
+
+	// Parse git config
+	gitConfig := GitConfig{
+		Remote:     "origin",
+		Clean:      true,
+		Commit:     true,
+		Repository: true,
+	}
+	if gitRaw, ok := raw["git_config"].(map[string]any); ok {
+		gitParser := helpers.NewConfigParser(gitRaw)
+		gitConfig.Remote = gitParser.GetString("remote", "", gitConfig.Remote)
+		gitConfig.Clean = gitParser.GetBool("clean", gitConfig.Clean)
+		gitConfig.Commit = gitParser.GetBool("commit", gitConfig.Commit)
+		gitConfig.Repository = gitParser.GetBool("repository", gitConfig.Repository)
+	}
+
+	// Parse git commit config
+	gitCommitConfig := GitCommitConfig{
+		Message: DefaultCommitMessage,
+	}
+	if commitRaw, ok := raw["git_commit_config"].(map[string]any); ok {
+		commitParser := helpers.NewConfigParser(commitRaw)
+		gitCommitConfig.Message = commitParser.GetString("message", "", gitCommitConfig.Message)
+		gitCommitConfig.AuthorName = commitParser.GetString("author_name", "", "")
+		gitCommitConfig.AuthorEmail = commitParser.GetString("author_email", "", "")
+	}
+
+	// Parse step hooks
+	stepHooks := StepHooksConfig{
+		UpdateVersion: string(plugin.HookPrePublish),
+		Changelog:     string(plugin.HookPrePublish),
+		GitCommit:     string(plugin.HookPrePublish),
+	}
+	if hooksRaw, ok := raw["step_hooks"].(map[string]any); ok {
+		hooksParser := helpers.NewConfigParser(hooksRaw)
+		stepHooks.UpdateVersion = hooksParser.GetString("update_version", "", stepHooks.UpdateVersion)
+		stepHooks.Changelog = hooksParser.GetString("changelog", "", stepHooks.Changelog)
+		stepHooks.GitCommit = hooksParser.GetString("git_commit", "", stepHooks.GitCommit)
+	}
+
+	// Parse publish retry policy
+	retryConfig := RetryConfig{
+		MaxAttempts:  DefaultRetryAttempts,
+		InitialDelay: DefaultRetryInitialDelay,
+		MaxDelay:     DefaultRetryMaxDelay,
+	}
+	if retryRaw, ok := raw["retry"].(map[string]any); ok {
+		retryParser := helpers.NewConfigParser(retryRaw)
+		retryConfig.MaxAttempts = retryParser.GetInt("max_attempts", retryConfig.MaxAttempts)
+		retryConfig.InitialDelay = retryParser.GetString("initial_delay", "", retryConfig.InitialDelay)
+		retryConfig.MaxDelay = retryParser.GetString("max_delay", "", retryConfig.MaxDelay)
+	}
+
 	// Parse exclude list
 	var exclude []string
 	if excludeRaw, ok := raw["exclude"].([]any); ok {
@@ -321,9 +942,39 @@ func (p *PubPlugin) parseConfig(raw map[string]any) *Config {
 		}
 	}
 
-	return &Config{
+	cfg := &Config{
 		PubspecPath:     parser.GetString("pubspec_path", "", "pubspec.yaml"),
 		UpdateVersion:   parser.GetBool("update_version", true),
+		PubGet:          parser.GetBool("pub_get", true),
+		PubGetConfig:    pubGetConfig,
+		Outdated:        parser.GetBool("outdated", false),
+		OutdatedConfig:  outdatedConfig,
+		CheckDowngrade:  parser.GetBool("check_downgrade", false),
+		DowngradeConfig: downgradeConfig,
+		Pana:            parser.GetBool("pana", false),
+		PanaConfig:      panaConfig,
+		Changelog:       parser.GetBool("changelog", false),
+		ChangelogConfig: changelogConfig,
+		PackageFiles:    packageFiles,
+		CheckArchive:    parser.GetBool("check_archive", false),
+		ArchiveConfig:   archiveConfig,
+		ScanSecrets:     parser.GetBool("scan_secrets", false),
+		CheckGit:        parser.GetBool("check_git", false),
+		GitConfig:       gitConfig,
+		GitCommit:       parser.GetBool("git_commit", false),
+		GitCommitConfig: gitCommitConfig,
+		StepHooks:       stepHooks,
+		Steps:           parseSteps(raw["steps"]),
+		Parallelism:     parser.GetInt("parallelism", DefaultParallelism),
+		BeforePublish:   parseCommands(raw["before_publish"]),
+		AfterPublish:    parseCommands(raw["after_publish"]),
+		Timeouts:        parseTimeouts(raw["timeouts"]),
+		Deadline:        parser.GetString("deadline", "", ""),
+		Retry:           retryConfig,
+		ReportPath:      parser.GetString("report_path", "", ""),
+		ReportMarkdown:  parser.GetBool("report_markdown", false),
+		Rollback:        parser.GetBool("rollback", true),
+		Retract:         parser.GetBool("retract", false),
 		CredentialsPath: parser.GetString("credentials_path", "", ""),
 		AccessToken:     parser.GetString("access_token", "PUB_ACCESS_TOKEN", ""),
 		HostedURL:       parser.GetString("hosted_url", "PUB_HOSTED_URL", ""),
@@ -337,4 +988,10 @@ func (p *PubPlugin) parseConfig(raw map[string]any) *Config {
 		Exclude:         exclude,
 		DryRun:          parser.GetBool("dry_run", false),
 	}
+	cfg.hostedURLFromEnv = cfg.HostedURL != "" && parser.GetString("hosted_url", "", "") == ""
+
+	// An explicit steps list decides which built-in steps run
+	applySteps(cfg)
+
+	return cfg
 }
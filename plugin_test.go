@@ -2,9 +2,14 @@ package main
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
 )
@@ -21,13 +26,17 @@ func TestPubPlugin_GetInfo(t *testing.T) {
 		t.Errorf("expected version %s, got %s", Version, info.Version)
 	}
 
-	if len(info.Hooks) != 2 {
-		t.Errorf("expected 2 hooks, got %d", len(info.Hooks))
+	if len(info.Hooks) != 6 {
+		t.Errorf("expected 6 hooks, got %d", len(info.Hooks))
 	}
 
 	hooks := map[plugin.Hook]bool{
+		plugin.HookPostVersion: false,
+		plugin.HookPostNotes:   false,
 		plugin.HookPrePublish:  false,
 		plugin.HookPostPublish: false,
+		plugin.HookOnSuccess:   false,
+		plugin.HookOnError:     false,
 	}
 	for _, h := range info.Hooks {
 		hooks[h] = true
@@ -54,6 +63,7 @@ func TestPubPlugin_ParseConfig(t *testing.T) {
 			expected: &Config{
 				PubspecPath:    "pubspec.yaml",
 				UpdateVersion:  true,
+				PubGet:         true,
 				Validate:       true,
 				Analyze:        true,
 				FormatCheck:    true,
@@ -72,6 +82,7 @@ func TestPubPlugin_ParseConfig(t *testing.T) {
 			config: map[string]any{
 				"pubspec_path":     "packages/core/pubspec.yaml",
 				"update_version":   false,
+				"pub_get":          false,
 				"access_token":     "test-token",
 				"hosted_url":       "https://private.pub.dev",
 				"validate":         false,
@@ -101,6 +112,35 @@ func TestPubPlugin_ParseConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "with pub get config",
+			config: map[string]any{
+				"pub_get_config": map[string]any{
+					"enforce_lockfile": true,
+					"offline":          true,
+				},
+			},
+			expected: &Config{
+				PubspecPath:   "pubspec.yaml",
+				UpdateVersion: true,
+				PubGet:        true,
+				PubGetConfig: PubGetConfig{
+					EnforceLockfile: true,
+					Offline:         true,
+				},
+				Validate:       true,
+				Analyze:        true,
+				FormatCheck:    true,
+				Test:           true,
+				DryRunValidate: true,
+				Force:          true,
+				TestConfig: TestConfig{
+					Platform:    "vm",
+					Concurrency: 4,
+					Coverage:    false,
+				},
+			},
+		},
 		{
 			name: "with test config",
 			config: map[string]any{
@@ -113,6 +153,7 @@ func TestPubPlugin_ParseConfig(t *testing.T) {
 			expected: &Config{
 				PubspecPath:    "pubspec.yaml",
 				UpdateVersion:  true,
+				PubGet:         true,
 				Validate:       true,
 				Analyze:        true,
 				FormatCheck:    true,
@@ -138,6 +179,12 @@ func TestPubPlugin_ParseConfig(t *testing.T) {
 			if cfg.UpdateVersion != tt.expected.UpdateVersion {
 				t.Errorf("expected update_version %v, got %v", tt.expected.UpdateVersion, cfg.UpdateVersion)
 			}
+			if cfg.PubGet != tt.expected.PubGet {
+				t.Errorf("expected pub_get %v, got %v", tt.expected.PubGet, cfg.PubGet)
+			}
+			if cfg.PubGetConfig != tt.expected.PubGetConfig {
+				t.Errorf("expected pub_get_config %+v, got %+v", tt.expected.PubGetConfig, cfg.PubGetConfig)
+			}
 			if cfg.AccessToken != tt.expected.AccessToken {
 				t.Errorf("expected access_token %s, got %s", tt.expected.AccessToken, cfg.AccessToken)
 			}
@@ -178,6 +225,176 @@ func TestPubPlugin_ParseConfig(t *testing.T) {
 	}
 }
 
+func TestPubPlugin_ParseConfig_Outdated(t *testing.T) {
+	p := &PubPlugin{}
+
+	cfg := p.parseConfig(map[string]any{})
+	if cfg.Outdated {
+		t.Error("expected outdated to be disabled by default")
+	}
+	expected := OutdatedConfig{
+		Discontinued: PolicyFail,
+		Retracted:    PolicyFail,
+		MajorBehind:  PolicyWarn,
+	}
+	if cfg.OutdatedConfig != expected {
+		t.Errorf("expected default outdated config %+v, got %+v", expected, cfg.OutdatedConfig)
+	}
+
+	cfg = p.parseConfig(map[string]any{
+		"outdated": true,
+		"outdated_config": map[string]any{
+			"discontinued":       "warn",
+			"major_behind":       "fail",
+			"include_dev":        true,
+			"include_transitive": true,
+		},
+	})
+	expected = OutdatedConfig{
+		Discontinued:      PolicyWarn,
+		Retracted:         PolicyFail,
+		MajorBehind:       PolicyFail,
+		IncludeDev:        true,
+		IncludeTransitive: true,
+	}
+	if !cfg.Outdated {
+		t.Error("expected outdated to be enabled")
+	}
+	if cfg.OutdatedConfig != expected {
+		t.Errorf("expected outdated config %+v, got %+v", expected, cfg.OutdatedConfig)
+	}
+}
+
+func TestPubPlugin_ParseConfig_Pana(t *testing.T) {
+	p := &PubPlugin{}
+
+	cfg := p.parseConfig(map[string]any{})
+	if cfg.Pana {
+		t.Error("expected pana to be disabled by default")
+	}
+	if cfg.PanaConfig.Executable != "pana" {
+		t.Errorf("expected default executable 'pana', got %s", cfg.PanaConfig.Executable)
+	}
+
+	cfg = p.parseConfig(map[string]any{
+		"pana": true,
+		"pana_config": map[string]any{
+			"executable":       "/opt/pana",
+			"min_score":        float64(120),
+			"fail_on_sections": []any{"analysis"},
+			"fail_on_checks":   []any{"CHANGELOG"},
+		},
+	})
+	if !cfg.Pana {
+		t.Error("expected pana to be enabled")
+	}
+	if cfg.PanaConfig.Executable != "/opt/pana" {
+		t.Errorf("expected executable '/opt/pana', got %s", cfg.PanaConfig.Executable)
+	}
+	if cfg.PanaConfig.MinScore != 120 {
+		t.Errorf("expected min_score 120, got %d", cfg.PanaConfig.MinScore)
+	}
+	if len(cfg.PanaConfig.FailOnSections) != 1 || cfg.PanaConfig.FailOnSections[0] != "analysis" {
+		t.Errorf("unexpected fail_on_sections: %v", cfg.PanaConfig.FailOnSections)
+	}
+	if len(cfg.PanaConfig.FailOnChecks) != 1 || cfg.PanaConfig.FailOnChecks[0] != "CHANGELOG" {
+		t.Errorf("unexpected fail_on_checks: %v", cfg.PanaConfig.FailOnChecks)
+	}
+}
+
+func TestPubPlugin_ParseConfig_Git(t *testing.T) {
+	p := &PubPlugin{}
+
+	cfg := p.parseConfig(map[string]any{})
+	if cfg.CheckGit {
+		t.Error("expected check_git to be disabled by default")
+	}
+	expected := GitConfig{Remote: "origin", Clean: true, Commit: true, Repository: true}
+	if cfg.GitConfig != expected {
+		t.Errorf("expected default git config %+v, got %+v", expected, cfg.GitConfig)
+	}
+
+	cfg = p.parseConfig(map[string]any{
+		"check_git": true,
+		"git_config": map[string]any{
+			"remote":     "upstream",
+			"repository": false,
+		},
+	})
+	expected = GitConfig{Remote: "upstream", Clean: true, Commit: true}
+	if !cfg.CheckGit {
+		t.Error("expected check_git to be enabled")
+	}
+	if cfg.GitConfig != expected {
+		t.Errorf("expected git config %+v, got %+v", expected, cfg.GitConfig)
+	}
+}
+
+func TestPubPlugin_ParseConfig_Retry(t *testing.T) {
+	p := &PubPlugin{}
+
+	cfg := p.parseConfig(map[string]any{})
+	expected := RetryConfig{MaxAttempts: 3, InitialDelay: "2s", MaxDelay: "30s"}
+	if cfg.Retry != expected {
+		t.Errorf("expected default retry config %+v, got %+v", expected, cfg.Retry)
+	}
+
+	cfg = p.parseConfig(map[string]any{
+		"retry": map[string]any{"max_attempts": 1, "initial_delay": "500ms"},
+	})
+	expected = RetryConfig{MaxAttempts: 1, InitialDelay: "500ms", MaxDelay: "30s"}
+	if cfg.Retry != expected {
+		t.Errorf("expected retry config %+v, got %+v", expected, cfg.Retry)
+	}
+}
+
+func TestPubPlugin_ParseConfig_GitCommit(t *testing.T) {
+	p := &PubPlugin{}
+
+	cfg := p.parseConfig(map[string]any{})
+	if cfg.GitCommit {
+		t.Error("expected git_commit to be disabled by default")
+	}
+	if cfg.GitCommitConfig.Message != DefaultCommitMessage {
+		t.Errorf("expected default message %q, got %q", DefaultCommitMessage, cfg.GitCommitConfig.Message)
+	}
+
+	cfg = p.parseConfig(map[string]any{
+		"git_commit": true,
+		"git_commit_config": map[string]any{
+			"message":      "release {{.Version}}",
+			"author_name":  "Release Bot",
+			"author_email": "release@example.com",
+		},
+	})
+	expected := GitCommitConfig{
+		Message:     "release {{.Version}}",
+		AuthorName:  "Release Bot",
+		AuthorEmail: "release@example.com",
+	}
+	if !cfg.GitCommit {
+		t.Error("expected git_commit to be enabled")
+	}
+	if cfg.GitCommitConfig != expected {
+		t.Errorf("expected git commit config %+v, got %+v", expected, cfg.GitCommitConfig)
+	}
+}
+
+func TestPluginModifiedFiles(t *testing.T) {
+	cfg := &Config{UpdateVersion: true, PubGet: true, Changelog: true}
+	got := strings.Join(pluginModifiedFiles("pkg/pubspec.yaml", cfg), ",")
+	if got != "pubspec.yaml,pubspec.lock" {
+		t.Errorf("unexpected modified files: %s", got)
+	}
+
+	cfg.ChangelogConfig.Generate = true
+	cfg.PubGet = false
+	got = strings.Join(pluginModifiedFiles("pubspec.yaml", cfg), ",")
+	if got != "pubspec.yaml,CHANGELOG.md" {
+		t.Errorf("unexpected modified files: %s", got)
+	}
+}
+
 func TestPubPlugin_Validate(t *testing.T) {
 	p := &PubPlugin{}
 
@@ -207,6 +424,127 @@ environment:
 			},
 			wantErrors: false,
 		},
+		{
+			name: "invalid outdated policy",
+			config: map[string]any{
+				"pubspec_path": pubspecPath,
+				"outdated_config": map[string]any{
+					"discontinued": "explode",
+				},
+			},
+			wantErrors: true,
+			errorField: "outdated_config.discontinued",
+		},
+		{
+			name: "invalid commit message template",
+			config: map[string]any{
+				"pubspec_path": pubspecPath,
+				"git_commit":   true,
+				"git_commit_config": map[string]any{
+					"message": "release {{.Version",
+				},
+			},
+			wantErrors: true,
+			errorField: "git_commit_config.message",
+		},
+		{
+			name: "commit before version bump",
+			config: map[string]any{
+				"pubspec_path": pubspecPath,
+				"step_hooks": map[string]any{
+					"update_version": "post-notes",
+					"git_commit":     "post-version",
+				},
+			},
+			wantErrors: true,
+			errorField: "step_hooks.git_commit",
+		},
+		{
+			name: "changelog generated before release notes",
+			config: map[string]any{
+				"pubspec_path":     pubspecPath,
+				"changelog":        true,
+				"changelog_config": map[string]any{"generate": true},
+				"step_hooks": map[string]any{
+					"update_version": "post-version",
+					"changelog":      "post-version",
+				},
+			},
+			wantErrors: true,
+			errorField: "step_hooks.changelog",
+		},
+		{
+			name: "invalid parallelism",
+			config: map[string]any{
+				"pubspec_path": pubspecPath,
+				"parallelism":  0,
+			},
+			wantErrors: true,
+			errorField: "parallelism",
+		},
+		{
+			name: "before_publish without run",
+			config: map[string]any{
+				"pubspec_path":   pubspecPath,
+				"before_publish": []any{map[string]any{"timeout": "1m"}},
+			},
+			wantErrors: true,
+			errorField: "before_publish[0]",
+		},
+		{
+			name: "invalid timeout",
+			config: map[string]any{
+				"pubspec_path": pubspecPath,
+				"timeouts":     map[string]any{"test": "soon"},
+			},
+			wantErrors: true,
+			errorField: "timeouts.test",
+		},
+		{
+			name: "negative archive largest",
+			config: map[string]any{
+				"pubspec_path":   pubspecPath,
+				"archive_config": map[string]any{"largest": -1},
+			},
+			wantErrors: true,
+			errorField: "archive_config.largest",
+		},
+		{
+			name: "invalid retry attempts",
+			config: map[string]any{
+				"pubspec_path": pubspecPath,
+				"retry":        map[string]any{"max_attempts": 0},
+			},
+			wantErrors: true,
+			errorField: "retry.max_attempts",
+		},
+		{
+			name: "unknown step",
+			config: map[string]any{
+				"pubspec_path": pubspecPath,
+				"steps":        []any{"analyze", "lint"},
+			},
+			wantErrors: true,
+			errorField: "steps[1]",
+		},
+		{
+			name: "markdown report without path",
+			config: map[string]any{
+				"pubspec_path":    pubspecPath,
+				"report_markdown": true,
+			},
+			wantErrors: true,
+			errorField: "report_markdown",
+		},
+		{
+			name: "markdown report path",
+			config: map[string]any{
+				"pubspec_path": pubspecPath,
+				"report_path":  "build/release.md",
+			},
+			wantErrors: true,
+			errorField: "report_path",
+		},
 		{
 			name: "missing pubspec",
 			config: map[string]any{
@@ -294,6 +632,12 @@ environment:
 	if !resp.Success {
 		t.Errorf("PrePublish should succeed in dry-run mode: %s", resp.Message)
 	}
+	if resp.Outputs["package"] != "test_package" || resp.Outputs["version"] != "2.0.0" {
+		t.Errorf("expected release outputs, got %+v", resp.Outputs)
+	}
+	if steps, ok := resp.Outputs["steps"].([]StepOutput); !ok || len(steps) != 1 || steps[0].Name != "pub_get" {
+		t.Errorf("expected step outputs, got %+v", resp.Outputs["steps"])
+	}
 
 	// Test PostPublish
 	req.Hook = plugin.HookPostPublish
@@ -304,4 +648,540 @@ environment:
 	if !resp.Success {
 		t.Errorf("PostPublish should succeed in dry-run mode: %s", resp.Message)
 	}
+	if resp.Outputs["registry"] != DefaultRegistryURL || resp.Outputs["published"] != false || resp.Outputs["dry_run"] != true {
+		t.Errorf("unexpected dry-run outputs: %+v", resp.Outputs)
+	}
+}
+
+func TestPubPlugin_Execute_PubGetInPackageDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as a stand-in for dart")
+	}
+
+	// Stand in for dart, recording where and how it was run
+	binDir := t.TempDir()
+	record := filepath.Join(t.TempDir(), "dart.txt")
+	script := "#!/bin/sh\necho \"$(pwd) $@\" >> " + record + "\n"
+	if err := os.WriteFile(filepath.Join(binDir, "dart"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	// The package lives outside the working directory
+	packageDir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubspec := "name: test_package\nversion: 1.0.0\n"
+	if err := os.WriteFile(filepath.Join(packageDir, "pubspec.yaml"), []byte(pubspec), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &PubPlugin{}
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPrePublish,
+		Context: plugin.ReleaseContext{Version: "1.0.0"},
+		Config: map[string]any{
+			"pubspec_path": filepath.Join(packageDir, "pubspec.yaml"),
+			"steps":        []any{"pub_get"},
+		},
+	})
+	if err != nil || !resp.Success {
+		t.Fatalf("PrePublish failed: %v %+v", err, resp)
+	}
+
+	got, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatalf("expected dart to run: %v", err)
+	}
+	if want := packageDir + " pub get\n"; string(got) != want {
+		t.Errorf("expected pub get to run in the package directory %q, got %q", want, got)
+	}
+}
+
+func TestPubPlugin_Execute_PublishToNone(t *testing.T) {
+	p := &PubPlugin{}
+
+	tempDir := t.TempDir()
+	pubspecPath := filepath.Join(tempDir, "pubspec.yaml")
+	pubspec := `name: internal_package
+version: 1.0.0
+description: An internal package that must never be uploaded to any package registry at all
+publish_to: none
+environment:
+  sdk: '>=3.0.0 <4.0.0'
+`
+	if err := os.WriteFile(pubspecPath, []byte(pubspec), 0644); err != nil {
+		t.Fatalf("failed to create pubspec: %v", err)
+	}
+
+	req := plugin.ExecuteRequest{
+		Hook:    plugin.HookPostPublish,
+		Context: plugin.ReleaseContext{Version: "1.1.0"},
+		Config: map[string]any{
+			"pubspec_path": pubspecPath,
+		},
+	}
+	resp, err := p.Execute(context.Background(), req)
+	if err != nil {
+		t.Fatalf("PostPublish failed: %v", err)
+	}
+	if !resp.Success {
+		t.Errorf("PostPublish should succeed for publish_to: none: %s", resp.Message)
+	}
+	if !strings.Contains(resp.Message, "publish_to: none") {
+		t.Errorf("expected skip message, got %s", resp.Message)
+	}
+	if resp.Outputs["published"] != false {
+		t.Errorf("expected published output to be false, got %+v", resp.Outputs)
+	}
+}
+
+func TestResolveRegistry(t *testing.T) {
+	tests := []struct {
+		name      string
+		publishTo string
+		hostedURL string
+		expected  string
+	}{
+		{"default", "", "", DefaultRegistryURL},
+		{"hosted url", "", "https://pub.example.com", "https://pub.example.com"},
+		{"publish_to wins", "https://pub.internal.dev", "https://pub.example.com", "https://pub.internal.dev"},
+		{"publish_to none", "none", "https://pub.example.com", "https://pub.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resolveRegistry(&Pubspec{PublishTo: tt.publishTo}, &Config{HostedURL: tt.hostedURL})
+			if got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCheckRegistryConflict(t *testing.T) {
+	tests := []struct {
+		name      string
+		publishTo string
+		hostedURL string
+		envURL    string
+		severity  Severity
+	}{
+		{"no hosted url", "https://pub.internal.dev", "", "", ""},
+		{"no publish_to", "", "https://pub.example.com", "", ""},
+		{"matching urls", "https://pub.example.com/", "https://pub.example.com", "", ""},
+		{"conflicting urls", "https://pub.internal.dev", "https://pub.example.com", "", SeverityError},
+		{"publish_to none", "none", "https://pub.example.com", "", SeverityWarning},
+		{"mirror from the environment", "https://pub.internal.dev", "", "https://mirror.example.com", SeverityWarning},
+		{"matching mirror from the environment", "https://pub.internal.dev", "", "https://pub.internal.dev", ""},
+		{"config wins over the environment", "https://pub.internal.dev", "https://pub.example.com", "https://pub.internal.dev", SeverityError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PUB_HOSTED_URL", tt.envURL)
+			raw := map[string]any{}
+			if tt.hostedURL != "" {
+				raw["hosted_url"] = tt.hostedURL
+			}
+			cfg := (&PubPlugin{}).parseConfig(raw)
+			issues := checkRegistryConflict(&Pubspec{PublishTo: tt.publishTo}, cfg)
+			if tt.severity == "" {
+				if len(issues) != 0 {
+					t.Errorf("unexpected issues: %v", issues)
+				}
+				return
+			}
+			if len(issues) != 1 {
+				t.Fatalf("expected 1 issue, got %v", issues)
+			}
+			if issues[0].Severity != tt.severity || issues[0].Rule != "publish-to-conflict" {
+				t.Errorf("unexpected issue: %+v", issues[0])
+			}
+		})
+	}
+}
+
+func TestPubPlugin_Execute_Changelog(t *testing.T) {
+	p := &PubPlugin{}
+
+	tempDir := t.TempDir()
+	pubspecPath := filepath.Join(tempDir, "pubspec.yaml")
+	pubspec := `name: test_package
+version: 1.0.0
+description: A test package for testing the pub plugin implementation with sufficient length
+environment:
+  sdk: '>=3.0.0 <4.0.0'
+`
+	if err := os.WriteFile(pubspecPath, []byte(pubspec), 0644); err != nil {
+		t.Fatalf("failed to create pubspec: %v", err)
+	}
+	changelogPath := filepath.Join(tempDir, ChangelogFile)
+	if err := os.WriteFile(changelogPath, []byte("# Changelog\n\n## 1.0.0\n\n- Initial release\n"), 0644); err != nil {
+		t.Fatalf("failed to create changelog: %v", err)
+	}
+
+	config := map[string]any{
+		"pubspec_path":     pubspecPath,
+		"pub_get":          false,
+		"update_version":   false,
+		"analyze":          false,
+		"format_check":     false,
+		"test":             false,
+		"dry_run_validate": false,
+		"changelog":        true,
+	}
+	req := plugin.ExecuteRequest{
+		Hook: plugin.HookPrePublish,
+		Context: plugin.ReleaseContext{
+			Version:      "1.1.0",
+			ReleaseNotes: "- Added streaming support",
+		},
+		Config: config,
+	}
+
+	resp, err := p.Execute(context.Background(), req)
+	if err != nil {
+		t.Fatalf("PrePublish failed: %v", err)
+	}
+	if resp.Success {
+		t.Error("expected PrePublish to fail without a changelog entry")
+	}
+
+	config["changelog_config"] = map[string]any{"generate": true}
+	req.Context.ReleaseNotes = ""
+	resp, err = p.Execute(context.Background(), req)
+	if err != nil {
+		t.Fatalf("PrePublish failed: %v", err)
+	}
+	if resp.Success || !strings.Contains(resp.Message, "no release notes") {
+		t.Errorf("expected PrePublish to fail without release notes, got %+v", resp)
+	}
+
+	req.Context.ReleaseNotes = "- Added streaming support"
+	resp, err = p.Execute(context.Background(), req)
+	if err != nil {
+		t.Fatalf("PrePublish failed: %v", err)
+	}
+	if !resp.Success {
+		t.Fatalf("expected PrePublish to succeed: %s", resp.Message)
+	}
+
+	data, err := os.ReadFile(changelogPath)
+	if err != nil {
+		t.Fatalf("failed to read changelog: %v", err)
+	}
+	expected := "# Changelog\n\n## 1.1.0\n\n- Added streaming support\n\n## 1.0.0\n\n- Initial release\n"
+	if string(data) != expected {
+		t.Errorf("expected:\n%s\n\ngot:\n%s", expected, string(data))
+	}
+}
+
+func TestPubPlugin_Execute_Rollback(t *testing.T) {
+	p := &PubPlugin{}
+
+	tempDir := t.TempDir()
+	pubspecPath := filepath.Join(tempDir, "pubspec.yaml")
+	pubspec := `name: test_package
+version: 1.0.0
+description: A test package for testing the pub plugin implementation with sufficient length
+environment:
+  sdk: '>=3.0.0 <4.0.0'
+`
+	if err := os.WriteFile(pubspecPath, []byte(pubspec), 0644); err != nil {
+		t.Fatalf("failed to create pubspec: %v", err)
+	}
+
+	config := map[string]any{
+		"pubspec_path":     pubspecPath,
+		"pub_get":          false,
+		"analyze":          false,
+		"format_check":     false,
+		"test":             false,
+		"dry_run_validate": false,
+		"changelog":        true,
+		"changelog_config": map[string]any{"generate": true},
+	}
+	req := plugin.ExecuteRequest{
+		Hook:    plugin.HookPrePublish,
+		Context: plugin.ReleaseContext{Version: "1.1.0", ReleaseNotes: "- Added streaming support"},
+		Config:  config,
+	}
+	readPubspec := func() string {
+		data, err := os.ReadFile(pubspecPath)
+		if err != nil {
+			t.Fatalf("failed to read pubspec: %v", err)
+		}
+		return string(data)
+	}
+
+	// A failing step after the version bump restores the pubspec within the hook
+	config["scan_secrets"] = true
+	if err := os.WriteFile(filepath.Join(tempDir, "key.pem"), []byte("-----BEGIN "+"PRIVATE KEY-----\n"), 0644); err != nil {
+		t.Fatalf("failed to create key: %v", err)
+	}
+	resp, err := p.Execute(context.Background(), req)
+	if err != nil {
+		t.Fatalf("PrePublish failed: %v", err)
+	}
+	if resp.Success {
+		t.Fatal("expected PrePublish to fail on the secret scan")
+	}
+	if !strings.Contains(resp.Message, "Rolled back: pubspec.yaml, CHANGELOG.md") {
+		t.Errorf("expected rollback in message, got: %s", resp.Message)
+	}
+	if rolledBack, _ := resp.Outputs["rolled_back"].([]string); strings.Join(rolledBack, ",") != "pubspec.yaml,CHANGELOG.md" {
+		t.Errorf("expected rolled_back output, got %+v", resp.Outputs["rolled_back"])
+	}
+	if got := readPubspec(); got != pubspec {
+		t.Errorf("expected pubspec to be restored, got:\n%s", got)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, ChangelogFile)); !os.IsNotExist(err) {
+		t.Errorf("expected generated changelog to be removed, got err %v", err)
+	}
+
+	// A later pipeline failure restores the pubspec through the error hook
+	config["scan_secrets"] = false
+	resp, err = p.Execute(context.Background(), req)
+	if err != nil || !resp.Success {
+		t.Fatalf("expected PrePublish to succeed: %v %+v", err, resp)
+	}
+	if !strings.Contains(readPubspec(), "version: 1.1.0") {
+		t.Fatal("expected version to be bumped")
+	}
+
+	req.Hook = plugin.HookOnError
+	resp, err = p.Execute(context.Background(), req)
+	if err != nil || !resp.Success {
+		t.Fatalf("expected OnError to succeed: %v %+v", err, resp)
+	}
+	if got := readPubspec(); got != pubspec {
+		t.Errorf("expected pubspec to be restored, got:\n%s", got)
+	}
+
+	resp, err = p.Execute(context.Background(), req)
+	if err != nil || !resp.Success || resp.Message != "Nothing to roll back" {
+		t.Errorf("expected nothing to roll back, got %v %+v", err, resp)
+	}
+}
+
+func TestPubPlugin_Execute_Retract(t *testing.T) {
+	p := &PubPlugin{}
+
+	var retracted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		retracted = append(retracted, r.Method+" "+r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tempDir := t.TempDir()
+	config := map[string]any{
+		"pubspec_path": filepath.Join(tempDir, "pubspec.yaml"),
+		"retract":      true,
+		"access_token": "token",
+	}
+	req := plugin.ExecuteRequest{
+		Hook:    plugin.HookOnError,
+		Context: plugin.ReleaseContext{Version: "1.1.0"},
+		Config:  config,
+	}
+
+	// Nothing was published by this run
+	resp, err := p.Execute(context.Background(), req)
+	if err != nil || !resp.Success {
+		t.Fatalf("expected OnError to succeed: %v %+v", err, resp)
+	}
+	if len(retracted) != 0 {
+		t.Errorf("expected no retraction, got %v", retracted)
+	}
+
+	record := &PublishRecord{Package: "test_package", Version: "1.1.0", Registry: server.URL, PublishedAt: time.Now()}
+	if err := SavePublishRecord(tempDir, record); err != nil {
+		t.Fatalf("failed to save record: %v", err)
+	}
+
+	req.DryRun = true
+	resp, err = p.Execute(context.Background(), req)
+	if err != nil || !resp.Success || !strings.Contains(resp.Message, "[DRY-RUN] Would retract test_package@1.1.0") {
+		t.Fatalf("unexpected dry-run response: %v %+v", err, resp)
+	}
+	if len(retracted) != 0 {
+		t.Errorf("expected no retraction in dry-run, got %v", retracted)
+	}
+
+	req.DryRun = false
+	resp, err = p.Execute(context.Background(), req)
+	if err != nil || !resp.Success {
+		t.Fatalf("expected OnError to succeed: %v %+v", err, resp)
+	}
+	if len(retracted) != 1 || retracted[0] != "PUT /api/packages/test_package/versions/1.1.0/options" {
+		t.Errorf("unexpected retract calls: %v", retracted)
+	}
+	if !strings.Contains(resp.Message, "Retracted test_package@1.1.0") {
+		t.Errorf("unexpected message: %s", resp.Message)
+	}
+	if record, _ := LoadPublishRecord(tempDir); record != nil {
+		t.Errorf("expected publish record to be removed, got %+v", record)
+	}
+
+	// Retraction is opt-in
+	if err := SavePublishRecord(tempDir, record); err != nil {
+		t.Fatalf("failed to save record: %v", err)
+	}
+	config["retract"] = false
+	if _, err := p.Execute(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(retracted) != 1 {
+		t.Errorf("expected no retraction when disabled, got %v", retracted)
+	}
+}
+
+func TestPubPlugin_Execute_AfterPublishFailureKeepsVersion(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as a stand-in for dart")
+	}
+
+	// Stand in for dart so the publish itself succeeds
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "dart"), []byte("#!/bin/sh\nexit 0\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	var retracted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			retracted = append(retracted, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	tempDir := t.TempDir()
+	pubspecPath := filepath.Join(tempDir, "pubspec.yaml")
+	pubspec := `name: test_package
+version: 1.1.0
+description: A test package for testing the pub plugin implementation with sufficient length
+publish_to: ` + server.URL + `
+environment:
+  sdk: '>=3.0.0 <4.0.0'
+`
+	if err := os.WriteFile(pubspecPath, []byte(pubspec), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &PubPlugin{}
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPostPublish,
+		Context: plugin.ReleaseContext{Version: "1.1.0"},
+		Config: map[string]any{
+			"pubspec_path":  pubspecPath,
+			"retract":       true,
+			"access_token":  "token",
+			"after_publish": []any{"exit 3", "echo skipped"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.Success || resp.Outputs["published"] != true {
+		t.Fatalf("expected a failing after_publish command to keep the hook successful, got %+v", resp)
+	}
+	if !strings.Contains(resp.Message, "warning: after-publish command failed") {
+		t.Errorf("expected a warning in the message, got %s", resp.Message)
+	}
+	findings, _ := resp.Outputs["findings"].([]Finding)
+	if len(findings) != 1 || findings[0].Step != "after_publish" || findings[0].Severity != SeverityWarning {
+		t.Errorf("expected an after_publish warning, got %+v", resp.Outputs["findings"])
+	}
+	if len(retracted) != 0 {
+		t.Errorf("expected no retraction, got %v", retracted)
+	}
+}
+
+func TestPubPlugin_Execute_StepHooks(t *testing.T) {
+	p := &PubPlugin{}
+
+	tempDir := t.TempDir()
+	pubspecPath := filepath.Join(tempDir, "pubspec.yaml")
+	pubspec := `name: test_package
+version: 1.0.0
+description: A test package for testing the pub plugin implementation with sufficient length
+environment:
+  sdk: '>=3.0.0 <4.0.0'
+`
+	if err := os.WriteFile(pubspecPath, []byte(pubspec), 0644); err != nil {
+		t.Fatalf("failed to create pubspec: %v", err)
+	}
+
+	config := map[string]any{
+		"pubspec_path":     pubspecPath,
+		"pub_get":          false,
+		"analyze":          false,
+		"format_check":     false,
+		"test":             false,
+		"dry_run_validate": false,
+		"changelog":        true,
+		"changelog_config": map[string]any{"generate": true},
+		"step_hooks": map[string]any{
+			"update_version": "post-version",
+			"changelog":      "post-notes",
+		},
+	}
+	execute := func(hook plugin.Hook) *plugin.ExecuteResponse {
+		t.Helper()
+		resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+			Hook:    hook,
+			Context: plugin.ReleaseContext{Version: "1.1.0", ReleaseNotes: "- Added streaming support"},
+			Config:  config,
+		})
+		if err != nil {
+			t.Fatalf("%s failed: %v", hook, err)
+		}
+		return resp
+	}
+
+	if resp := execute(plugin.HookPostVersion); !resp.Success {
+		t.Fatalf("expected PostVersion to succeed: %s", resp.Message)
+	}
+	data, _ := os.ReadFile(pubspecPath)
+	if !strings.Contains(string(data), "version: 1.1.0") {
+		t.Errorf("expected version to be bumped in PostVersion, got:\n%s", data)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, ChangelogFile)); !os.IsNotExist(err) {
+		t.Error("expected changelog not to be generated in PostVersion")
+	}
+
+	if resp := execute(plugin.HookPostNotes); !resp.Success {
+		t.Fatalf("expected PostNotes to succeed: %s", resp.Message)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, ChangelogFile)); err != nil {
+		t.Errorf("expected changelog to be generated in PostNotes: %v", err)
+	}
+
+	if resp := execute(plugin.HookPrePublish); !resp.Success {
+		t.Fatalf("expected PrePublish to succeed: %s", resp.Message)
+	}
+
+	// The snapshot spans the hooks, so a later failure restores both files
+	if resp := execute(plugin.HookOnError); !resp.Success || !strings.Contains(resp.Message, "pubspec.yaml, CHANGELOG.md") {
+		t.Fatalf("expected OnError to roll back both files: %+v", resp)
+	}
+	data, _ = os.ReadFile(pubspecPath)
+	if string(data) != pubspec {
+		t.Errorf("expected pubspec to be restored, got:\n%s", data)
+	}
+
+	// OnSuccess clears the state kept for rollback
+	execute(plugin.HookPostVersion)
+	resp := execute(plugin.HookOnSuccess)
+	if !resp.Success || resp.Message != "Release of test_package@1.1.0 completed" {
+		t.Errorf("unexpected OnSuccess response: %+v", resp)
+	}
+	if s, _ := LoadSnapshot(tempDir); s != nil {
+		t.Errorf("expected snapshot to be discarded, got %+v", s)
+	}
 }
@@ -0,0 +1,9 @@
+//go:build !unix && !windows
+
+package main
+
+import "os/exec"
+
+// setProcessGroup is a no-op on platforms without process groups; only the
+// command itself is killed on cancellation.
+func setProcessGroup(_ *exec.Cmd) {}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
 
 	"gopkg.in/yaml.v3"
@@ -10,15 +11,38 @@ import (
 
 // Pubspec represents a parsed pubspec.yaml file.
 type Pubspec struct {
-	Name         string            `yaml:"name"`
-	Version      string            `yaml:"version"`
-	Description  string            `yaml:"description"`
-	Homepage     string            `yaml:"homepage,omitempty"`
-	Repository   string            `yaml:"repository,omitempty"`
-	Environment  map[string]string `yaml:"environment"`
-	Dependencies map[string]any    `yaml:"dependencies"`
-	DevDeps      map[string]any    `yaml:"dev_dependencies"`
-	Flutter      map[string]any    `yaml:"flutter,omitempty"`
+	Name                string              `yaml:"name"`
+	Version             string              `yaml:"version"`
+	Description         string              `yaml:"description"`
+	Homepage            string              `yaml:"homepage,omitempty"`
+	Repository          string              `yaml:"repository,omitempty"`
+	IssueTracker        string              `yaml:"issue_tracker,omitempty"`
+	Documentation       string              `yaml:"documentation,omitempty"`
+	Topics              []string            `yaml:"topics,omitempty"`
+	Screenshots         []PubspecScreenshot `yaml:"screenshots,omitempty"`
+	Funding             []string            `yaml:"funding,omitempty"`
+	Platforms           map[string]any      `yaml:"platforms,omitempty"`
+	FalseSecrets        []string            `yaml:"false_secrets,omitempty"`
+	PublishTo           string              `yaml:"publish_to,omitempty"`
+	Executables         map[string]string   `yaml:"executables,omitempty"`
+	Environment         map[string]string   `yaml:"environment"`
+	Dependencies        map[string]any      `yaml:"dependencies"`
+	DevDeps             map[string]any      `yaml:"dev_dependencies"`
+	DependencyOverrides map[string]any      `yaml:"dependency_overrides,omitempty"`
+	Resolution          string              `yaml:"resolution,omitempty"`
+	Flutter             map[string]any      `yaml:"flutter,omitempty"`
+
+	// dir is the directory containing the pubspec, used to resolve
+	// package-relative paths such as screenshots and executables.
+	dir string
+	// node is the parsed YAML tree, used to locate validation issues.
+	node *yaml.Node
+}
+
+// PubspecScreenshot represents an entry in the screenshots field.
+type PubspecScreenshot struct {
+	Description string `yaml:"description"`
+	Path        string `yaml:"path"`
 }
 
 // ParsePubspec parses a pubspec.yaml file.
@@ -28,10 +52,17 @@ func ParsePubspec(path string) (*Pubspec, error) {
 		return nil, fmt.Errorf("failed to read pubspec.yaml: %w", err)
 	}
 
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("failed to parse pubspec.yaml: %w", err)
+	}
+
 	var pubspec Pubspec
-	if err := yaml.Unmarshal(data, &pubspec); err != nil {
+	if err := node.Decode(&pubspec); err != nil {
 		return nil, fmt.Errorf("failed to parse pubspec.yaml: %w", err)
 	}
+	pubspec.dir = filepath.Dir(path)
+	pubspec.node = &node
 
 	return &pubspec, nil
 }
@@ -58,39 +89,43 @@ func UpdateVersion(path, version string) error {
 	return nil
 }
 
-// ValidatePubspec validates pubspec.yaml contents for pub.dev requirements.
-func ValidatePubspec(pubspec *Pubspec) error {
+// ValidatePubspec validates pubspec.yaml contents for pub.dev requirements
+// and returns every problem found.
+func ValidatePubspec(pubspec *Pubspec) []ValidationIssue {
+	l := &issueList{pubspec: pubspec}
+
 	if pubspec.Name == "" {
-		return fmt.Errorf("package name is required")
+		l.errorf("name", "name-required", "package name is required")
 	}
 
 	if pubspec.Version == "" {
-		return fmt.Errorf("version is required")
-	}
-
-	if pubspec.Description == "" {
-		return fmt.Errorf("description is required for pub.dev")
+		l.errorf("version", "version-required", "version is required")
 	}
 
 	// Pub.dev description requirements
-	if len(pubspec.Description) < 60 {
-		return fmt.Errorf("description should be at least 60 characters (currently %d)", len(pubspec.Description))
-	}
-	if len(pubspec.Description) > 180 {
-		return fmt.Errorf("description should be at most 180 characters (currently %d)", len(pubspec.Description))
+	switch {
+	case pubspec.Description == "":
+		l.errorf("description", "description-required", "description is required for pub.dev")
+	case len(pubspec.Description) < 60:
+		l.errorf("description", "description-too-short",
+			"description should be at least 60 characters (currently %d)", len(pubspec.Description))
+	case len(pubspec.Description) > 180:
+		l.errorf("description", "description-too-long",
+			"description should be at most 180 characters (currently %d)", len(pubspec.Description))
 	}
 
 	// Check SDK constraint
 	if pubspec.Environment == nil {
-		return fmt.Errorf("environment section is required")
+		l.errorf("environment", "environment-required", "environment section is required")
+	} else if sdk, ok := pubspec.Environment["sdk"]; !ok || sdk == "" {
+		l.errorf("environment.sdk", "sdk-constraint-required", "SDK constraint is required in environment section")
 	}
+	validateEnvironment(l)
 
-	sdk, ok := pubspec.Environment["sdk"]
-	if !ok || sdk == "" {
-		return fmt.Errorf("SDK constraint is required in environment section")
-	}
+	validateMetadata(l)
+	validateDependencies(l)
 
-	return nil
+	return l.issues
 }
 
 // IsFlutterPackage checks if the pubspec indicates a Flutter package.
@@ -108,6 +143,20 @@ func IsFlutterPackage(pubspec *Pubspec) bool {
 	return false
 }
 
+// IsPublishable reports whether publish_to allows publishing the package.
+func (p *Pubspec) IsPublishable() bool {
+	return p.PublishTo != "none"
+}
+
+// PublishRegistry returns the registry URL declared via publish_to, or an
+// empty string when the package targets the default registry.
+func (p *Pubspec) PublishRegistry() string {
+	if p.PublishTo == "none" {
+		return ""
+	}
+	return p.PublishTo
+}
+
 // GetPackageName returns the package name from pubspec.
 func (p *Pubspec) GetPackageName() string {
 	return p.Name
@@ -82,6 +82,65 @@ flutter:
 	}
 }
 
+func TestParsePubspec_Metadata(t *testing.T) {
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, "pubspec.yaml")
+	content := `name: my_package
+version: 2.0.0
+topics:
+  - http
+screenshots:
+  - description: Main screen
+    path: doc/screen.png
+platforms:
+  android:
+  ios:
+publish_to: none
+executables:
+  my_tool:
+  other: tool
+dependency_overrides:
+  http: ^1.0.0
+resolution: workspace
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	pubspec, err := ParsePubspec(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(pubspec.Topics) != 1 || pubspec.Topics[0] != "http" {
+		t.Errorf("unexpected topics: %v", pubspec.Topics)
+	}
+	if len(pubspec.Screenshots) != 1 || pubspec.Screenshots[0].Path != "doc/screen.png" {
+		t.Errorf("unexpected screenshots: %+v", pubspec.Screenshots)
+	}
+	if _, ok := pubspec.Platforms["ios"]; !ok || len(pubspec.Platforms) != 2 {
+		t.Errorf("unexpected platforms: %v", pubspec.Platforms)
+	}
+	if pubspec.PublishTo != "none" {
+		t.Errorf("expected publish_to none, got %s", pubspec.PublishTo)
+	}
+	if script, ok := pubspec.Executables["my_tool"]; !ok || script != "" {
+		t.Errorf("unexpected executables: %v", pubspec.Executables)
+	}
+	if pubspec.Executables["other"] != "tool" {
+		t.Errorf("unexpected executables: %v", pubspec.Executables)
+	}
+	if pubspec.DependencyOverrides["http"] != "^1.0.0" {
+		t.Errorf("unexpected dependency_overrides: %v", pubspec.DependencyOverrides)
+	}
+	if pubspec.Resolution != "workspace" {
+		t.Errorf("expected resolution workspace, got %s", pubspec.Resolution)
+	}
+	if pubspec.dir != tempDir {
+		t.Errorf("expected dir %s, got %s", tempDir, pubspec.dir)
+	}
+}
+
 func TestUpdateVersion(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -259,24 +318,83 @@ func TestValidatePubspec(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := ValidatePubspec(tt.pubspec)
+			issues := ValidatePubspec(tt.pubspec)
 
 			if tt.wantErr {
-				if err == nil {
-					t.Error("expected error, got nil")
-				} else if tt.errMsg != "" && err.Error() != tt.errMsg {
-					t.Errorf("expected error message '%s', got '%s'", tt.errMsg, err.Error())
+				if len(issues) != 1 {
+					t.Fatalf("expected 1 issue, got %d: %v", len(issues), issues)
+				}
+				if issues[0].Severity != SeverityError {
+					t.Errorf("expected error severity, got %s", issues[0].Severity)
+				}
+				if tt.errMsg != "" && issues[0].Message != tt.errMsg {
+					t.Errorf("expected error message '%s', got '%s'", tt.errMsg, issues[0].Message)
 				}
 				return
 			}
 
-			if err != nil {
-				t.Errorf("unexpected error: %v", err)
+			if len(issues) != 0 {
+				t.Errorf("unexpected issues: %v", issues)
 			}
 		})
 	}
 }
 
+func TestValidatePubspec_Aggregates(t *testing.T) {
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, "pubspec.yaml")
+	content := `name: my_package
+description: Too short
+topics:
+  - http
+  - Bad_Topic
+environment:
+  flutter: '>=3.10.0'
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	pubspec, err := ParsePubspec(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	issues := ValidatePubspec(pubspec)
+
+	expected := []struct {
+		field  string
+		rule   string
+		line   int
+		column int
+	}{
+		{"version", "version-required", 0, 0},
+		{"description", "description-too-short", 2, 1},
+		{"environment.sdk", "sdk-constraint-required", 6, 1},
+		{"topics[1]", "topic-format", 5, 5},
+	}
+
+	if len(issues) != len(expected) {
+		t.Fatalf("expected %d issues, got %d: %v", len(expected), len(issues), issues)
+	}
+	for i, want := range expected {
+		got := issues[i]
+		if got.Field != want.field || got.Rule != want.rule {
+			t.Errorf("issue %d: expected %s/%s, got %s/%s", i, want.field, want.rule, got.Field, got.Rule)
+		}
+		if got.Line != want.line || got.Column != want.column {
+			t.Errorf("issue %d: expected position %d:%d, got %d:%d", i, want.line, want.column, got.Line, got.Column)
+		}
+	}
+
+	if !HasErrors(issues) {
+		t.Error("expected HasErrors to be true")
+	}
+	if got := issues[1].String(); got != "pubspec.yaml:2:1: description should be at least 60 characters (currently 9)" {
+		t.Errorf("unexpected issue string: %s", got)
+	}
+}
+
 func TestIsFlutterPackage(t *testing.T) {
 	tests := []struct {
 		name     string
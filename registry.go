@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// PubRegistryClient calls the pub repository HTTP API.
+type PubRegistryClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// NewPubRegistryClient creates a client for the registry at baseURL,
+// authenticating with an OAuth or registry access token.
+func NewPubRegistryClient(baseURL, token string) *PubRegistryClient {
+	return &PubRegistryClient{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		token:      token,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Retract marks a published version as retracted.
+func (c *PubRegistryClient) Retract(ctx context.Context, pkg, version string) error {
+	return c.setVersionOptions(ctx, pkg, version, map[string]any{"isRetracted": true})
+}
+
+// PublishedArchive locates the archive of a published version.
+type PublishedArchive struct {
+	URL string
+	// SHA256 is the registry's checksum of the archive, if it reports one.
+	SHA256 string
+}
+
+// VersionArchive returns the download URL and checksum of a published
+// version's archive.
+func (c *PubRegistryClient) VersionArchive(ctx context.Context, pkg, version string) (*PublishedArchive, error) {
+	endpoint := fmt.Sprintf("%s/api/packages/%s/versions/%s",
+		c.baseURL, url.PathEscape(pkg), url.PathEscape(version))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.pub.v2+json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request to %s failed: %w", c.baseURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, registryError(resp)
+	}
+
+	var body struct {
+		ArchiveURL    string `json:"archive_url"`
+		ArchiveSHA256 string `json:"archive_sha256"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1024*1024)).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to parse version info: %w", err)
+	}
+	if body.ArchiveURL == "" {
+		return nil, fmt.Errorf("registry returned no archive_url for %s@%s", pkg, version)
+	}
+	return &PublishedArchive{URL: body.ArchiveURL, SHA256: body.ArchiveSHA256}, nil
+}
+
+func (c *PubRegistryClient) setVersionOptions(ctx context.Context, pkg, version string, options map[string]any) error {
+	body, err := json.Marshal(options)
+	if err != nil {
+		return fmt.Errorf("failed to encode version options: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/api/packages/%s/versions/%s/options",
+		c.baseURL, url.PathEscape(pkg), url.PathEscape(version))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.pub.v2+json")
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request to %s failed: %w", c.baseURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	return registryError(resp)
+}
+
+// registryError builds an error from a failed API response, using the
+// message from the standard {"error": {"code", "message"}} body if present.
+func registryError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+	var body struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &body); err == nil && body.Error.Message != "" {
+		return fmt.Errorf("registry returned %s: %s (%s)", resp.Status, body.Error.Message, body.Error.Code)
+	}
+	if text := strings.TrimSpace(string(data)); text != "" {
+		return fmt.Errorf("registry returned %s: %s", resp.Status, text)
+	}
+	return fmt.Errorf("registry returned %s", resp.Status)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPubRegistryClient_Retract(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{name: "success", status: http.StatusOK, body: `{"isRetracted":true}`},
+		{
+			name:    "api error",
+			status:  http.StatusForbidden,
+			body:    `{"error":{"code":"InsufficientPermissions","message":"not an uploader"}}`,
+			wantErr: "not an uploader (InsufficientPermissions)",
+		},
+		{name: "plain error", status: http.StatusBadGateway, body: "bad gateway", wantErr: "502 Bad Gateway: bad gateway"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath, gotAuth, gotAccept string
+			var gotBody map[string]any
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPut {
+					t.Errorf("expected PUT, got %s", r.Method)
+				}
+				gotPath = r.URL.Path
+				gotAuth = r.Header.Get("Authorization")
+				gotAccept = r.Header.Get("Accept")
+				_ = json.NewDecoder(r.Body).Decode(&gotBody)
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewPubRegistryClient(server.URL+"/", "secret-token")
+			err := client.Retract(context.Background(), "my_package", "1.2.0+1")
+
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+
+			if gotPath != "/api/packages/my_package/versions/1.2.0+1/options" {
+				t.Errorf("unexpected path %s", gotPath)
+			}
+			if gotAuth != "Bearer secret-token" {
+				t.Errorf("unexpected authorization %q", gotAuth)
+			}
+			if gotAccept != "application/vnd.pub.v2+json" {
+				t.Errorf("unexpected accept %q", gotAccept)
+			}
+			if gotBody["isRetracted"] != true {
+				t.Errorf("unexpected body %v", gotBody)
+			}
+		})
+	}
+}
+
+func TestPubRegistryClient_VersionArchive(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    *PublishedArchive
+		wantErr string
+	}{
+		{
+			name:   "success",
+			status: http.StatusOK,
+			body:   `{"version":"1.2.0","archive_url":"https://pub.example.com/packages/my_package/versions/1.2.0.tar.gz","archive_sha256":"ab12"}`,
+			want:   &PublishedArchive{URL: "https://pub.example.com/packages/my_package/versions/1.2.0.tar.gz", SHA256: "ab12"},
+		},
+		{
+			name:   "no checksum",
+			status: http.StatusOK,
+			body:   `{"version":"1.2.0","archive_url":"https://pub.example.com/1.2.0.tar.gz"}`,
+			want:   &PublishedArchive{URL: "https://pub.example.com/1.2.0.tar.gz"},
+		},
+		{name: "missing url", status: http.StatusOK, body: `{"version":"1.2.0"}`, wantErr: "no archive_url"},
+		{
+			name:    "not found",
+			status:  http.StatusNotFound,
+			body:    `{"error":{"code":"NotFound","message":"version not found"}}`,
+			wantErr: "version not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath, gotAuth string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet {
+					t.Errorf("expected GET, got %s", r.Method)
+				}
+				gotPath = r.URL.Path
+				gotAuth = r.Header.Get("Authorization")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			got, err := NewPubRegistryClient(server.URL, "").VersionArchive(context.Background(), "my_package", "1.2.0")
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+			if gotPath != "/api/packages/my_package/versions/1.2.0" {
+				t.Errorf("unexpected path %s", gotPath)
+			}
+			if gotAuth != "" {
+				t.Errorf("expected no authorization without a token, got %q", gotAuth)
+			}
+		})
+	}
+}
@@ -0,0 +1,376 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// Report is the persistent record of what the plugin did for a release.
+// PrePublish and PostPublish each add their own entry to the same file.
+type Report struct {
+	Package       string       `json:"package"`
+	Version       string       `json:"version"`
+	PluginVersion string       `json:"plugin_version"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+	Hooks         []HookReport `json:"hooks"`
+}
+
+// HookReport records one hook execution.
+type HookReport struct {
+	Hook       string    `json:"hook"`
+	DryRun     bool      `json:"dry_run"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Success    bool      `json:"success"`
+	Message    string    `json:"message"`
+	// Tools maps each SDK to the output of its --version command.
+	Tools    map[string]string `json:"tools,omitempty"`
+	Commands []CommandRecord   `json:"commands"`
+	HookResults
+
+	commands *CommandLog
+}
+
+// HookResults is the part of a hook response's outputs kept in the report.
+type HookResults struct {
+	Steps         []StepOutput     `json:"steps,omitempty"`
+	Findings      []Finding        `json:"findings,omitempty"`
+	Analyzer      *AnalyzerSummary `json:"analyzer,omitempty"`
+	Tests         *TestSummary     `json:"tests,omitempty"`
+	Coverage      *CoverageSummary `json:"coverage,omitempty"`
+	Archive       *ArchiveOutput   `json:"archive,omitempty"`
+	RolledBack    []string         `json:"rolled_back,omitempty"`
+	Registry      string           `json:"registry,omitempty"`
+	Published     bool             `json:"published,omitempty"`
+	Attempts      []AttemptOutput  `json:"attempts,omitempty"`
+	PackageURL    string           `json:"package_url,omitempty"`
+	ArchiveURL    string           `json:"archive_url,omitempty"`
+	ArchiveSHA256 string           `json:"archive_sha256,omitempty"`
+}
+
+// CommandRecord describes a command the plugin ran.
+type CommandRecord struct {
+	Command    string    `json:"command"`
+	Dir        string    `json:"dir"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMS int64     `json:"duration_ms"`
+	// ExitCode is -1 if the command did not start or was killed.
+	ExitCode int    `json:"exit_code"`
+	Error    string `json:"error,omitempty"`
+}
+
+// CommandLog records the commands run by concurrent steps.
+type CommandLog struct {
+	mu      sync.Mutex
+	records []CommandRecord
+}
+
+func (l *CommandLog) add(cmd *exec.Cmd, start time.Time, err error) {
+	rec := CommandRecord{
+		Command:    formatCommand(cmd.Args),
+		Dir:        cmd.Dir,
+		StartedAt:  start.UTC(),
+		DurationMS: time.Since(start).Milliseconds(),
+		ExitCode:   -1,
+	}
+	if cmd.ProcessState != nil {
+		rec.ExitCode = cmd.ProcessState.ExitCode()
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.records = append(l.records, rec)
+}
+
+// Records returns the recorded commands in the order they started.
+func (l *CommandLog) Records() []CommandRecord {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	records := slices.Clone(l.records)
+	slices.SortStableFunc(records, func(a, b CommandRecord) int {
+		return a.StartedAt.Compare(b.StartedAt)
+	})
+	return records
+}
+
+// formatCommand joins a command line, quoting arguments that contain
+// spaces or quotes.
+func formatCommand(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'") {
+			arg = strconv.Quote(arg)
+		}
+		quoted[i] = arg
+	}
+	return strings.Join(quoted, " ")
+}
+
+// newHookReport starts the record of a hook execution. Commands run through
+// a DartCLI using its command log are recorded.
+func newHookReport(hook plugin.Hook, dryRun bool) *HookReport {
+	return &HookReport{
+		Hook:      string(hook),
+		DryRun:    dryRun,
+		StartedAt: time.Now().UTC(),
+		commands:  &CommandLog{},
+	}
+}
+
+// finish completes the record from the hook response.
+func (h *HookReport) finish(resp *plugin.ExecuteResponse) error {
+	h.FinishedAt = time.Now().UTC()
+	h.Success = resp.Success
+	h.Message = resp.Message
+	h.Commands = h.commands.Records()
+
+	// Outputs hold typed values; a JSON round trip picks the ones kept
+	data, err := json.Marshal(resp.Outputs)
+	if err != nil {
+		return fmt.Errorf("failed to encode outputs: %w", err)
+	}
+	if err := json.Unmarshal(data, &h.HookResults); err != nil {
+		return fmt.Errorf("failed to decode outputs: %w", err)
+	}
+	return nil
+}
+
+// LoadReport reads a report written by an earlier hook. It returns nil if
+// the file does not exist.
+func LoadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report: %w", err)
+	}
+
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse report %s: %w", path, err)
+	}
+	return &report, nil
+}
+
+// setHook adds the record of a hook, replacing the record of an earlier
+// run of the same hook.
+func (r *Report) setHook(h HookReport) {
+	for i := range r.Hooks {
+		if r.Hooks[i].Hook == h.Hook {
+			r.Hooks[i] = h
+			return
+		}
+	}
+	r.Hooks = append(r.Hooks, h)
+}
+
+// WriteReport writes the report as JSON to path and, if markdown is set, as
+// Markdown next to it.
+func WriteReport(path string, report *Report, markdown bool) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create report directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	if markdown {
+		if err := os.WriteFile(markdownReportPath(path), []byte(FormatReportMarkdown(report)), 0644); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+	}
+	return nil
+}
+
+// markdownReportPath replaces the extension of the JSON report path with .md.
+func markdownReportPath(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ".md"
+}
+
+// FormatReportMarkdown renders the report for human readers.
+func FormatReportMarkdown(report *Report) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Release report: %s %s\n\n", report.Package, report.Version)
+	fmt.Fprintf(&sb, "Written by plugin version %s at %s.\n", report.PluginVersion, report.UpdatedAt.Format(time.RFC3339))
+
+	for _, h := range report.Hooks {
+		result := "succeeded"
+		if !h.Success {
+			result = "failed"
+		}
+		fmt.Fprintf(&sb, "\n## %s\n\n", h.Hook)
+		fmt.Fprintf(&sb, "- Result: %s\n", result)
+		fmt.Fprintf(&sb, "- Dry run: %t\n", h.DryRun)
+		fmt.Fprintf(&sb, "- Started: %s\n", h.StartedAt.Format(time.RFC3339))
+		fmt.Fprintf(&sb, "- Finished: %s (%s)\n", h.FinishedAt.Format(time.RFC3339), h.FinishedAt.Sub(h.StartedAt).Round(time.Millisecond))
+		for _, tool := range slices.Sorted(maps.Keys(h.Tools)) {
+			fmt.Fprintf(&sb, "- %s: %s\n", tool, firstLine(h.Tools[tool]))
+		}
+		writeResultsMarkdown(&sb, h.HookResults)
+
+		if !h.Success {
+			fmt.Fprintf(&sb, "\n### Error\n\n```\n%s\n```\n", h.Message)
+		}
+
+		if len(h.Steps) > 0 {
+			sb.WriteString("\n### Steps\n\n| Step | Status | Duration | Error |\n|------|--------|----------|-------|\n")
+			for _, s := range h.Steps {
+				fmt.Fprintf(&sb, "| %s | %s | %s | %s |\n", s.Name, s.Status, time.Duration(s.DurationMS)*time.Millisecond, markdownCell(s.Error))
+			}
+		}
+
+		if len(h.Findings) > 0 {
+			sb.WriteString("\n### Findings\n\n")
+			for _, f := range h.Findings {
+				if !strings.Contains(f.Message, "\n") {
+					fmt.Fprintf(&sb, "- **%s** `%s`: %s\n", f.Severity, f.Step, f.Message)
+					continue
+				}
+				fmt.Fprintf(&sb, "- **%s** `%s`:\n\n  ```\n", f.Severity, f.Step)
+				for _, line := range strings.Split(f.Message, "\n") {
+					fmt.Fprintf(&sb, "  %s\n", line)
+				}
+				sb.WriteString("  ```\n")
+			}
+		}
+
+		if len(h.Attempts) > 0 {
+			sb.WriteString("\n### Publish attempts\n\n| Attempt | Result | Duration | Error |\n|---------|--------|----------|-------|\n")
+			for _, a := range h.Attempts {
+				kind := a.Kind
+				if kind == "" {
+					kind = "published"
+				}
+				fmt.Fprintf(&sb, "| %d | %s | %s | %s |\n", a.Number, kind, time.Duration(a.DurationMS)*time.Millisecond, markdownCell(firstLine(a.Error)))
+			}
+		}
+
+		sb.WriteString("\n### Commands\n\n")
+		if len(h.Commands) == 0 {
+			sb.WriteString("No commands were run.\n")
+			continue
+		}
+		sb.WriteString("| Command | Exit code | Duration | Started |\n|---------|-----------|----------|---------|\n")
+		for _, c := range h.Commands {
+			fmt.Fprintf(&sb, "| `%s` | %d | %s | %s |\n", markdownCell(c.Command), c.ExitCode,
+				time.Duration(c.DurationMS)*time.Millisecond, c.StartedAt.Format(time.RFC3339))
+		}
+	}
+	return sb.String()
+}
+
+// writeResultsMarkdown lists the summaries a hook reported.
+func writeResultsMarkdown(sb *strings.Builder, res HookResults) {
+	if a := res.Analyzer; a != nil {
+		fmt.Fprintf(sb, "- Analyzer: %d issue(s) (%d errors, %d warnings, %d infos)\n", a.Issues, a.Errors, a.Warnings, a.Infos)
+	}
+	if t := res.Tests; t != nil {
+		fmt.Fprintf(sb, "- Tests: %d passed, %d skipped, %d failed\n", t.Passed, t.Skipped, t.Failed)
+	}
+	if c := res.Coverage; c != nil {
+		fmt.Fprintf(sb, "- Coverage: %.1f%% (%d of %d lines)\n", c.Percent, c.Covered, c.Lines)
+	}
+	if a := res.Archive; a != nil {
+		fmt.Fprintf(sb, "- Archive: %d files, %s (%s unpacked), sha256 `%s`\n", a.Files, formatBytes(a.Size), formatBytes(a.UnpackedSize), a.SHA256)
+	}
+	if res.Registry != "" {
+		fmt.Fprintf(sb, "- Registry: %s\n", res.Registry)
+		fmt.Fprintf(sb, "- Published: %t\n", res.Published)
+	}
+	if res.PackageURL != "" {
+		fmt.Fprintf(sb, "- Package: %s\n", res.PackageURL)
+	}
+	if res.ArchiveURL != "" {
+		fmt.Fprintf(sb, "- Published archive: %s\n", res.ArchiveURL)
+	}
+	if res.ArchiveSHA256 != "" {
+		fmt.Fprintf(sb, "- Published archive sha256: `%s`\n", res.ArchiveSHA256)
+	}
+	if len(res.RolledBack) > 0 {
+		fmt.Fprintf(sb, "- Rolled back: %s\n", strings.Join(res.RolledBack, ", "))
+	}
+}
+
+// markdownCell keeps a value on one table row.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+// saveReport adds the hook record to the report at cfg.ReportPath. A report
+// that cannot be written is a warning: the hook's work is already done.
+func (p *PubPlugin) saveReport(ctx context.Context, releaseCtx *plugin.ReleaseContext, cfg *Config, h *HookReport, logger *slog.Logger, resp *plugin.ExecuteResponse, err error) (*plugin.ExecuteResponse, error) {
+	if cfg.ReportPath == "" || resp == nil {
+		return resp, err
+	}
+
+	if writeErr := p.writeReport(ctx, releaseCtx, cfg, h, logger, resp); writeErr != nil {
+		logger.Warn("Failed to write release report", "path", cfg.ReportPath, "error", writeErr)
+		resp.Message = fmt.Sprintf("%s\n\nwarning: failed to write release report: %v", resp.Message, writeErr)
+	}
+	return resp, err
+}
+
+func (p *PubPlugin) writeReport(ctx context.Context, releaseCtx *plugin.ReleaseContext, cfg *Config, h *HookReport, logger *slog.Logger, resp *plugin.ExecuteResponse) error {
+	if err := h.finish(resp); err != nil {
+		return err
+	}
+
+	pubspecPath := cfg.PubspecPath
+	if pubspecPath == "" {
+		pubspecPath = "pubspec.yaml"
+	}
+	var name string
+	if pubspec, err := ParsePubspec(pubspecPath); err == nil {
+		name = pubspec.Name
+		dartVersion, flutterVersion := localSDKVersions(ctx, pubspec, filepath.Dir(pubspecPath))
+		h.Tools = make(map[string]string)
+		if dartVersion != "" {
+			h.Tools["dart"] = dartVersion
+		}
+		if flutterVersion != "" {
+			h.Tools["flutter"] = flutterVersion
+		}
+	}
+
+	report, err := LoadReport(cfg.ReportPath)
+	if err != nil {
+		logger.Warn("Replacing unreadable release report", "error", err)
+	}
+	// A report left over from another release is replaced
+	if report == nil || report.Package != name || report.Version != releaseCtx.Version {
+		report = &Report{Package: name, Version: releaseCtx.Version}
+	}
+	report.PluginVersion = Version
+	report.UpdatedAt = h.FinishedAt
+	report.setHook(*h)
+
+	return WriteReport(cfg.ReportPath, report, cfg.ReportMarkdown)
+}
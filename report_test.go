@@ -0,0 +1,271 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestFormatCommand(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"dart", "pub", "publish", "--force"}, "dart pub publish --force"},
+		{[]string{"sh", "-c", "echo $RELICTA_VERSION > out.txt"}, `sh -c "echo $RELICTA_VERSION > out.txt"`},
+		{[]string{"dart", "test", "--coverage="}, "dart test --coverage="},
+		{[]string{"cmd", ""}, `cmd ""`},
+	}
+
+	for _, tt := range tests {
+		if got := formatCommand(tt.args); got != tt.want {
+			t.Errorf("formatCommand(%q) = %s, want %s", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestCommandLog(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("commands use POSIX shell syntax")
+	}
+
+	dir := t.TempDir()
+	log := &CommandLog{}
+	dart := NewDartCLI(dir)
+	dart.SetCommandLog(log)
+
+	if err := dart.Shell(context.Background(), "echo ok"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := dart.Shell(context.Background(), "exit 3"); err == nil {
+		t.Fatal("expected the failing command to return an error")
+	}
+	if _, err := dart.output(context.Background(), "definitely-not-a-command"); err == nil {
+		t.Fatal("expected a missing command to return an error")
+	}
+
+	records := log.Records()
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %+v", records)
+	}
+	want := []struct {
+		command  string
+		exitCode int
+		failed   bool
+	}{
+		{`sh -c "echo ok"`, 0, false},
+		{`sh -c "exit 3"`, 3, true},
+		{"definitely-not-a-command", -1, true},
+	}
+	for i, w := range want {
+		rec := records[i]
+		if rec.Command != w.command || rec.ExitCode != w.exitCode || (rec.Error != "") != w.failed {
+			t.Errorf("record %d: expected %+v, got %+v", i, w, rec)
+		}
+		if rec.Dir != dir || rec.StartedAt.IsZero() {
+			t.Errorf("record %d: missing dir or start time: %+v", i, rec)
+		}
+	}
+}
+
+func TestReport_SetHook(t *testing.T) {
+	report := &Report{}
+	report.setHook(HookReport{Hook: "pre-publish", Message: "first"})
+	report.setHook(HookReport{Hook: "post-publish"})
+	report.setHook(HookReport{Hook: "pre-publish", Message: "rerun"})
+
+	if len(report.Hooks) != 2 || report.Hooks[0].Message != "rerun" || report.Hooks[1].Hook != "post-publish" {
+		t.Errorf("expected a rerun to replace its hook in place, got %+v", report.Hooks)
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reports", "release.json")
+	report := &Report{
+		Package: "my_pkg",
+		Version: "1.2.0",
+		Hooks:   []HookReport{{Hook: "pre-publish", Success: true, HookResults: HookResults{Archive: &ArchiveOutput{SHA256: "ab12"}}}},
+	}
+
+	if err := WriteReport(path, report, true); err != nil {
+		t.Fatalf("WriteReport failed: %v", err)
+	}
+
+	loaded, err := LoadReport(path)
+	if err != nil {
+		t.Fatalf("LoadReport failed: %v", err)
+	}
+	if loaded.Package != "my_pkg" || len(loaded.Hooks) != 1 || loaded.Hooks[0].Archive == nil || loaded.Hooks[0].Archive.SHA256 != "ab12" {
+		t.Errorf("unexpected report: %+v", loaded)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(path), "release.md")); err != nil {
+		t.Errorf("expected markdown report: %v", err)
+	}
+
+	if missing, err := LoadReport(filepath.Join(filepath.Dir(path), "missing.json")); missing != nil || err != nil {
+		t.Errorf("expected no report for a missing file, got %+v, %v", missing, err)
+	}
+}
+
+func TestFormatReportMarkdown(t *testing.T) {
+	started := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	report := &Report{
+		Package:       "my_pkg",
+		Version:       "1.2.0",
+		PluginVersion: "0.1.0",
+		UpdatedAt:     started.Add(time.Minute),
+		Hooks: []HookReport{
+			{
+				Hook:       "pre-publish",
+				StartedAt:  started,
+				FinishedAt: started.Add(42 * time.Second),
+				Success:    true,
+				Tools:      map[string]string{"dart": "Dart SDK version: 3.5.0 (stable)"},
+				Commands: []CommandRecord{
+					{Command: "dart analyze --fatal-infos", ExitCode: 0, DurationMS: 1500, StartedAt: started},
+				},
+				HookResults: HookResults{
+					Steps:    []StepOutput{{Name: "analyze", Status: "passed", DurationMS: 1500}},
+					Findings: []Finding{{Step: "outdated", Severity: SeverityWarning, Message: "http | is behind"}},
+					Tests:    &TestSummary{Passed: 12},
+					Archive:  &ArchiveOutput{SHA256: "ab12", Files: 3, Size: 2048, UnpackedSize: 8192},
+				},
+			},
+			{
+				Hook:        "post-publish",
+				DryRun:      true,
+				StartedAt:   started.Add(time.Minute),
+				FinishedAt:  started.Add(time.Minute),
+				Success:     true,
+				HookResults: HookResults{Registry: "https://pub.dev"},
+			},
+		},
+	}
+
+	got := FormatReportMarkdown(report)
+	for _, want := range []string{
+		"# Release report: my_pkg 1.2.0\n",
+		"Written by plugin version 0.1.0 at 2026-03-01T12:01:00Z.\n",
+		"## pre-publish\n\n- Result: succeeded\n- Dry run: false\n",
+		"- Finished: 2026-03-01T12:00:42Z (42s)\n",
+		"- dart: Dart SDK version: 3.5.0 (stable)\n",
+		"- Tests: 12 passed, 0 skipped, 0 failed\n",
+		"- Archive: 3 files, 2.0 KB (8.0 KB unpacked), sha256 `ab12`\n",
+		"| analyze | passed | 1.5s |  |\n",
+		"- **warning** `outdated`: http | is behind\n",
+		"| `dart analyze --fatal-infos` | 0 | 1.5s | 2026-03-01T12:00:00Z |\n",
+		"## post-publish\n\n- Result: succeeded\n- Dry run: true\n",
+		"- Registry: https://pub.dev\n- Published: false\n",
+		"No commands were run.\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected markdown to contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestPubPlugin_Execute_Report(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("steps use POSIX shell syntax")
+	}
+
+	p := &PubPlugin{}
+	dir := t.TempDir()
+	pubspecPath := filepath.Join(dir, "pubspec.yaml")
+	pubspec := `name: test_package
+version: 1.0.0
+description: A test package for testing the pub plugin implementation with sufficient length
+environment:
+  sdk: '>=3.0.0 <4.0.0'
+`
+	if err := os.WriteFile(pubspecPath, []byte(pubspec), 0644); err != nil {
+		t.Fatal(err)
+	}
+	reportPath := filepath.Join(t.TempDir(), "audit", "release.json")
+
+	config := map[string]any{
+		"pubspec_path":    pubspecPath,
+		"report_path":     reportPath,
+		"report_markdown": true,
+		"steps": []any{
+			map[string]any{"name": "codegen", "run": "echo generated"},
+			map[string]any{"name": "docs", "run": "echo broken >&2; exit 3", "continue_on_error": true},
+		},
+	}
+	releaseCtx := plugin.ReleaseContext{Version: "2.0.0"}
+
+	resp, err := p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPrePublish,
+		Context: releaseCtx,
+		Config:  config,
+	})
+	if err != nil || !resp.Success {
+		t.Fatalf("PrePublish failed: %v %+v", err, resp)
+	}
+
+	resp, err = p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPostPublish,
+		Context: releaseCtx,
+		Config:  config,
+		DryRun:  true,
+	})
+	if err != nil || !resp.Success {
+		t.Fatalf("PostPublish failed: %v %+v", err, resp)
+	}
+
+	report, err := LoadReport(reportPath)
+	if err != nil || report == nil {
+		t.Fatalf("expected a report, got %+v, %v", report, err)
+	}
+	if report.Package != "test_package" || report.Version != "2.0.0" || report.PluginVersion != Version {
+		t.Errorf("unexpected report header: %+v", report)
+	}
+	if len(report.Hooks) != 2 {
+		t.Fatalf("expected both hooks in the report, got %+v", report.Hooks)
+	}
+
+	pre := report.Hooks[0]
+	if pre.Hook != "pre-publish" || !pre.Success || pre.DryRun || pre.FinishedAt.Before(pre.StartedAt) {
+		t.Errorf("unexpected pre-publish record: %+v", pre)
+	}
+	if len(pre.Commands) != 2 || pre.Commands[0].Command != `sh -c "echo generated"` || pre.Commands[1].ExitCode != 3 {
+		t.Errorf("expected both step commands, got %+v", pre.Commands)
+	}
+	if len(pre.Steps) != 2 || len(pre.Findings) != 1 || pre.Findings[0].Step != "docs" || pre.Findings[0].Severity != SeverityWarning {
+		t.Errorf("expected steps and a warning for docs, got %+v / %+v", pre.Steps, pre.Findings)
+	}
+	if pre.Archive == nil || len(pre.Archive.SHA256) != 64 {
+		t.Errorf("expected the archive checksum, got %+v", pre.Archive)
+	}
+
+	post := report.Hooks[1]
+	if post.Hook != "post-publish" || !post.DryRun || post.Published || post.Registry != DefaultRegistryURL {
+		t.Errorf("unexpected post-publish record: %+v", post)
+	}
+
+	markdown, err := os.ReadFile(filepath.Join(filepath.Dir(reportPath), "release.md"))
+	if err != nil || !strings.Contains(string(markdown), "## post-publish") {
+		t.Errorf("expected markdown report with both hooks, got %v", err)
+	}
+
+	// A report from another release is replaced rather than extended
+	releaseCtx.Version = "2.0.1"
+	resp, err = p.Execute(context.Background(), plugin.ExecuteRequest{
+		Hook:    plugin.HookPostPublish,
+		Context: releaseCtx,
+		Config:  config,
+		DryRun:  true,
+	})
+	if err != nil || !resp.Success {
+		t.Fatalf("PostPublish failed: %v %+v", err, resp)
+	}
+	if report, _ := LoadReport(reportPath); report == nil || report.Version != "2.0.1" || len(report.Hooks) != 1 {
+		t.Errorf("expected a fresh report for 2.0.1, got %+v", report)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// RetractWindow is how long after publishing pub.dev allows a retraction.
+const RetractWindow = 7 * 24 * time.Hour
+
+// publishRecordFile is the name of the publish record inside StateDir.
+const publishRecordFile = "published.json"
+
+// PublishRecord records a version published by this release, so that a
+// later failure can retract exactly what was published.
+type PublishRecord struct {
+	Package     string    `json:"package"`
+	Version     string    `json:"version"`
+	Registry    string    `json:"registry"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// CanRetract reports whether the version is still within the retraction window.
+func (r *PublishRecord) CanRetract(now time.Time) bool {
+	return now.Sub(r.PublishedAt) < RetractWindow
+}
+
+// SavePublishRecord writes the publish record for the package in dir.
+func SavePublishRecord(dir string, record *PublishRecord) error {
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode publish record: %w", err)
+	}
+
+	path := publishRecordPath(dir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write publish record: %w", err)
+	}
+	return nil
+}
+
+// LoadPublishRecord reads the publish record for the package in dir. It
+// returns nil if nothing was published.
+func LoadPublishRecord(dir string) (*PublishRecord, error) {
+	data, err := os.ReadFile(publishRecordPath(dir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read publish record: %w", err)
+	}
+
+	var record PublishRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to parse publish record: %w", err)
+	}
+	return &record, nil
+}
+
+// RemovePublishRecord deletes the publish record for the package in dir.
+func RemovePublishRecord(dir string) error {
+	if err := os.Remove(publishRecordPath(dir)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove publish record: %w", err)
+	}
+	return nil
+}
+
+func publishRecordPath(dir string) string {
+	return filepath.Join(dir, filepath.FromSlash(StateDir), publishRecordFile)
+}
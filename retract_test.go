@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPublishRecord(t *testing.T) {
+	dir := t.TempDir()
+
+	record, err := LoadPublishRecord(dir)
+	if err != nil || record != nil {
+		t.Fatalf("expected no record, got %v, %v", record, err)
+	}
+
+	published := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	want := &PublishRecord{Package: "my_package", Version: "1.2.0", Registry: DefaultRegistryURL, PublishedAt: published}
+	if err := SavePublishRecord(dir, want); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	record, err = LoadPublishRecord(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *record != *want {
+		t.Errorf("expected %+v, got %+v", want, record)
+	}
+
+	if !record.CanRetract(published.Add(6 * 24 * time.Hour)) {
+		t.Error("expected version to be retractable within 7 days")
+	}
+	if record.CanRetract(published.Add(RetractWindow)) {
+		t.Error("expected version not to be retractable after 7 days")
+	}
+
+	if err := RemovePublishRecord(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if record, _ := LoadPublishRecord(dir); record != nil {
+		t.Errorf("expected record to be removed, got %+v", record)
+	}
+	if err := RemovePublishRecord(dir); err != nil {
+		t.Errorf("expected removing a missing record to succeed, got %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Retry defaults.
+const (
+	DefaultRetryAttempts     = 3
+	DefaultRetryInitialDelay = "2s"
+	DefaultRetryMaxDelay     = "30s"
+)
+
+// RetryConfig defines how transient publish failures are retried.
+type RetryConfig struct {
+	// MaxAttempts is the total number of publish attempts; 1 disables retries.
+	MaxAttempts  int    `json:"max_attempts"`
+	InitialDelay string `json:"initial_delay"`
+	MaxDelay     string `json:"max_delay"`
+}
+
+// backoff returns the wait after the given failed attempt: the initial delay
+// doubled for every earlier attempt, capped at the maximum, with jitter so
+// that concurrent releases do not retry in lockstep.
+func (c RetryConfig) backoff(attempt int) time.Duration {
+	initial := parsePositiveDuration(c.InitialDelay)
+	limit := parsePositiveDuration(c.MaxDelay)
+	if limit == 0 {
+		limit = initial
+	}
+
+	delay := initial
+	for i := 1; i < attempt && delay < limit; i++ {
+		delay *= 2
+	}
+	delay = min(delay, limit)
+	if delay <= 0 {
+		return 0
+	}
+
+	// Wait between half and all of the delay
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// FailureKind classifies a failed publish attempt.
+type FailureKind string
+
+// Publish failure kinds.
+const (
+	// FailureRetryable is a network error, rate limit or server error.
+	FailureRetryable FailureKind = "retryable"
+	// FailurePermanent is a validation error or any failure not known to be transient.
+	FailurePermanent FailureKind = "permanent"
+	// FailureAlreadyExists means the registry already has the version.
+	FailureAlreadyExists FailureKind = "already-exists"
+)
+
+var alreadyExistsPattern = regexp.MustCompile(`(?i)already exists|already been (?:published|uploaded)`)
+
+// retryablePattern matches the errors pub reports for network failures,
+// rate limiting and registry server errors.
+var retryablePattern = regexp.MustCompile(`(?i)` + strings.Join([]string{
+	`SocketException`,
+	`HandshakeException`,
+	`TlsException`,
+	`ClientException`,
+	`Failed host lookup`,
+	`Connection (?:closed|reset|refused|terminated)`,
+	`network is unreachable`,
+	`Operation timed out`,
+	`\bHTTP(?:/\d(?:\.\d)?)?\s+(?:error\s+)?(?:429|5\d\d)\b`,
+	`\bstatus(?: code)?:?\s*(?:429|5\d\d)\b`,
+	`Too Many Requests`,
+	`Internal Server Error`,
+	`Bad Gateway`,
+	`Service Unavailable`,
+	`Gateway Time-?out`,
+}, "|"))
+
+// ClassifyPublishError decides from the output of a failed publish whether
+// retrying can help.
+func ClassifyPublishError(err error) FailureKind {
+	msg := err.Error()
+	switch {
+	case alreadyExistsPattern.MatchString(msg):
+		return FailureAlreadyExists
+	case retryablePattern.MatchString(msg):
+		return FailureRetryable
+	default:
+		return FailurePermanent
+	}
+}
+
+// PublishAttempt records one publish attempt.
+type PublishAttempt struct {
+	Number   int
+	Duration time.Duration
+	// Error and Kind are empty for a successful attempt.
+	Error string
+	Kind  FailureKind
+}
+
+// RetryPublish calls publish until it succeeds, fails permanently or runs
+// out of attempts, backing off between retryable failures. A version that
+// already exists after an earlier failed attempt was uploaded by that
+// attempt, so it counts as published. It returns every attempt made.
+func RetryPublish(ctx context.Context, cfg RetryConfig, logger *slog.Logger, publish func(ctx context.Context) error) ([]PublishAttempt, error) {
+	maxAttempts := max(cfg.MaxAttempts, 1)
+
+	var attempts []PublishAttempt
+	for n := 1; ; n++ {
+		start := time.Now()
+		err := publish(ctx)
+		attempt := PublishAttempt{Number: n, Duration: time.Since(start)}
+		if err == nil {
+			return append(attempts, attempt), nil
+		}
+		attempt.Error = err.Error()
+		attempt.Kind = ClassifyPublishError(err)
+		attempts = append(attempts, attempt)
+
+		switch {
+		case attempt.Kind == FailureAlreadyExists && n > 1:
+			logger.Warn("Version already exists after a failed attempt; treating it as published", "attempt", n)
+			return attempts, nil
+		case attempt.Kind != FailureRetryable || ctx.Err() != nil:
+			return attempts, err
+		case n >= maxAttempts:
+			return attempts, fmt.Errorf("giving up after %d attempts: %w", n, err)
+		}
+
+		delay := cfg.backoff(n)
+		logger.Warn("Publish failed, retrying", "attempt", n, "delay", delay, "error", err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return attempts, fmt.Errorf("%w while waiting to retry: %w", context.Cause(ctx), err)
+		case <-timer.C:
+		}
+	}
+}
+
+// FormatPublishAttempts renders one line per attempt.
+func FormatPublishAttempts(attempts []PublishAttempt) string {
+	var sb strings.Builder
+	for _, a := range attempts {
+		fmt.Fprintf(&sb, "attempt %d: ", a.Number)
+		if a.Error == "" {
+			fmt.Fprintf(&sb, "published in %s\n", a.Duration.Round(time.Millisecond))
+			continue
+		}
+		// Only the first line; the output tail follows in the error message
+		firstLine, _, _ := strings.Cut(a.Error, "\n")
+		fmt.Fprintf(&sb, "failed (%s) after %s: %s\n", a.Kind, a.Duration.Round(time.Millisecond), firstLine)
+	}
+	return sb.String()
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClassifyPublishError(t *testing.T) {
+	tests := []struct {
+		name   string
+		stderr string
+		want   FailureKind
+	}{
+		{"socket", "SocketException: Connection reset by peer (OS Error: Connection reset by peer, errno = 104)", FailureRetryable},
+		{"dns", "Failed host lookup: 'pub.dev'", FailureRetryable},
+		{"tls", "HandshakeException: Connection terminated during handshake", FailureRetryable},
+		{"server error", "Got HTTP 502 Bad Gateway from https://pub.dev/api/packages/versions/new", FailureRetryable},
+		{"status code", "Upload failed with status code: 503", FailureRetryable},
+		{"rate limited", "HTTP error 429: Too Many Requests", FailureRetryable},
+		{"validation", "Package validation found the following error:\n* Your pubspec.yaml is missing a description.", FailurePermanent},
+		{"auth", "Authentication failed!", FailurePermanent},
+		{"client error", "Got HTTP 403 Forbidden", FailurePermanent},
+		{"version number", "Version 5.0.3 of foo is invalid: exit status 65", FailurePermanent},
+		{"own timeout", "timed out after 5m0s", FailurePermanent},
+		{"exists", "Version 1.2.0 of package my_pkg already exists.", FailureAlreadyExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyPublishError(errors.New(tt.stderr)); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRetryConfig_Backoff(t *testing.T) {
+	cfg := RetryConfig{InitialDelay: "1s", MaxDelay: "5s"}
+
+	tests := []struct {
+		attempt int
+		base    time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 5 * time.Second},
+		{10, 5 * time.Second},
+	}
+	for _, tt := range tests {
+		for range 20 {
+			got := cfg.backoff(tt.attempt)
+			if got < tt.base/2 || got > tt.base {
+				t.Fatalf("attempt %d: expected delay in [%s, %s], got %s", tt.attempt, tt.base/2, tt.base, got)
+			}
+		}
+	}
+}
+
+func TestRetryPublish(t *testing.T) {
+	var (
+		network  = errors.New("SocketException: Connection refused")
+		invalid  = errors.New("Package validation found the following error")
+		existing = errors.New("Version 1.0.0 of package my_pkg already exists.")
+	)
+	cfg := RetryConfig{MaxAttempts: 3, InitialDelay: "1ms", MaxDelay: "2ms"}
+	logger := slog.New(slog.DiscardHandler)
+
+	tests := []struct {
+		name      string
+		results   []error
+		wantCalls int
+		wantErr   string
+		wantKinds []FailureKind
+	}{
+		{
+			name:      "first attempt succeeds",
+			results:   []error{nil},
+			wantCalls: 1,
+			wantKinds: []FailureKind{""},
+		},
+		{
+			name:      "transient failure recovers",
+			results:   []error{network, nil},
+			wantCalls: 2,
+			wantKinds: []FailureKind{FailureRetryable, ""},
+		},
+		{
+			name:      "permanent failure is not retried",
+			results:   []error{invalid},
+			wantCalls: 1,
+			wantErr:   "Package validation",
+			wantKinds: []FailureKind{FailurePermanent},
+		},
+		{
+			name:      "gives up after max attempts",
+			results:   []error{network, network, network},
+			wantCalls: 3,
+			wantErr:   "giving up after 3 attempts",
+			wantKinds: []FailureKind{FailureRetryable, FailureRetryable, FailureRetryable},
+		},
+		{
+			name:      "already exists after a lost response",
+			results:   []error{network, existing},
+			wantCalls: 2,
+			wantKinds: []FailureKind{FailureRetryable, FailureAlreadyExists},
+		},
+		{
+			name:      "already exists on first attempt",
+			results:   []error{existing},
+			wantCalls: 1,
+			wantErr:   "already exists",
+			wantKinds: []FailureKind{FailureAlreadyExists},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			attempts, err := RetryPublish(context.Background(), cfg, logger, func(context.Context) error {
+				calls++
+				return tt.results[calls-1]
+			})
+
+			if calls != tt.wantCalls {
+				t.Errorf("expected %d calls, got %d", tt.wantCalls, calls)
+			}
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if len(attempts) != len(tt.wantKinds) {
+				t.Fatalf("expected %d attempts, got %+v", len(tt.wantKinds), attempts)
+			}
+			for i, kind := range tt.wantKinds {
+				if attempts[i].Number != i+1 || attempts[i].Kind != kind {
+					t.Errorf("attempt %d: expected kind %q, got %+v", i+1, kind, attempts[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRetryPublish_Deadline(t *testing.T) {
+	cfg := RetryConfig{MaxAttempts: 5, InitialDelay: "1h", MaxDelay: "1h"}
+	ctx, cancel := withDeadline(context.Background(), &Config{Deadline: "50ms"})
+	defer cancel()
+
+	attempts, err := RetryPublish(ctx, cfg, slog.New(slog.DiscardHandler), func(context.Context) error {
+		return errors.New("HTTP 503 Service Unavailable")
+	})
+	if len(attempts) != 1 {
+		t.Errorf("expected a single attempt, got %+v", attempts)
+	}
+	if err == nil || !strings.Contains(err.Error(), "deadline of 50ms exceeded while waiting to retry") {
+		t.Errorf("expected deadline error, got %v", err)
+	}
+}
+
+func TestFormatPublishAttempts(t *testing.T) {
+	attempts := []PublishAttempt{
+		{Number: 1, Duration: 1500 * time.Millisecond, Error: "HTTP 503 Service Unavailable\n\nOutput:\n...", Kind: FailureRetryable},
+		{Number: 2, Duration: 2 * time.Second},
+	}
+
+	want := "attempt 1: failed (retryable) after 1.5s: HTTP 503 Service Unavailable\n" +
+		"attempt 2: published in 2s\n"
+	if got := FormatPublishAttempts(attempts); got != want {
+		t.Errorf("unexpected attempts:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// secretRule detects one kind of credential.
+type secretRule struct {
+	id      string
+	name    string
+	pattern *regexp.Regexp
+}
+
+// secretRules lists the credential formats the scanner detects.
+var secretRules = []secretRule{
+	{"private-key", "private key", regexp.MustCompile(`-----BEGIN (?:RSA |EC |DSA |OPENSSH |ENCRYPTED |PGP )?PRIVATE KEY(?: BLOCK)?-----`)},
+	{"aws-access-key", "AWS access key ID", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"aws-secret-key", "AWS secret access key", regexp.MustCompile(`(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?[A-Za-z0-9/+=]{40}\b`)},
+	{"gcp-api-key", "Google API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{"gcp-service-account", "Google service account key", regexp.MustCompile(`"type"\s*:\s*"service_account"`)},
+	{"google-oauth-refresh", "Google OAuth refresh token", regexp.MustCompile(`\b1//0[0-9A-Za-z_-]{40,}`)},
+	{"github-token", "GitHub token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{"gitlab-token", "GitLab token", regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}\b`)},
+	{"slack-token", "Slack token", regexp.MustCompile(`\bxox[abposr]-[0-9A-Za-z-]{10,}\b`)},
+	{"stripe-key", "Stripe secret key", regexp.MustCompile(`\b(?:sk|rk)_live_[0-9A-Za-z]{24,}\b`)},
+	{"azure-storage-key", "Azure storage account key", regexp.MustCompile(`AccountKey=[A-Za-z0-9/+]{86}==`)},
+}
+
+// SecretFinding is a potential credential found in a package file.
+type SecretFinding struct {
+	File string
+	Line int
+	Rule string
+	Name string
+	// Match is a redacted excerpt of the detected value.
+	Match string
+}
+
+// String formats the finding with its location.
+func (f SecretFinding) String() string {
+	return fmt.Sprintf("%s:%d: %s (%s)", f.File, f.Line, f.Name, f.Match)
+}
+
+// ScanSecrets scans the given package files for credentials. Files matching
+// the pubspec false_secrets patterns are skipped, as pub.dev does.
+func ScanSecrets(dir string, files []PackageFile, falseSecrets []string) ([]SecretFinding, error) {
+	allowed := newIgnoreRules("", falseSecrets)
+
+	var findings []SecretFinding
+	for _, f := range files {
+		if allowed.matchesPathOrParent(f.Path) {
+			continue
+		}
+
+		fileFindings, err := scanFile(filepath.Join(dir, filepath.FromSlash(f.Path)), f.Path)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, fileFindings...)
+	}
+
+	return findings, nil
+}
+
+func scanFile(path, rel string) ([]SecretFinding, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", rel, err)
+	}
+
+	// Skip binary files
+	if bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0 {
+		return nil, nil
+	}
+
+	// The file is already in memory, so lines of any length are scanned;
+	// minified assets often fit on a single line.
+	var findings []SecretFinding
+	rest := data
+	for line := 1; len(rest) > 0; line++ {
+		var text []byte
+		text, rest, _ = bytes.Cut(rest, []byte("\n"))
+		text = bytes.TrimSuffix(text, []byte("\r"))
+		for _, rule := range secretRules {
+			if match := rule.pattern.Find(text); match != nil {
+				findings = append(findings, SecretFinding{
+					File:  rel,
+					Line:  line,
+					Rule:  rule.id,
+					Name:  rule.name,
+					Match: redact(string(match)),
+				})
+			}
+		}
+	}
+
+	return findings, nil
+}
+
+// redact keeps a short prefix of a secret so findings can be identified
+// without repeating the credential.
+func redact(s string) string {
+	const visible = 8
+	if strings.HasPrefix(s, "-----") || len(s) <= visible {
+		return s
+	}
+	return s[:visible] + "..."
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// The fake credentials below are split so the test source itself does not
+// trip secret scanners.
+var (
+	fakeAWSKey    = "AKIA" + "IOSFODNN7EXAMPLE"
+	fakeGitHubPAT = "ghp_" + strings.Repeat("a1B2", 9)
+	fakeGoogleKey = "AIza" + strings.Repeat("x", 35)
+	fakeSlackTok  = "xoxb-" + "1234567890-abcdefghij"
+	fakeStripeKey = "sk_live_" + strings.Repeat("4eC3", 6)
+	fakePEMHeader = "-----BEGIN RSA " + "PRIVATE KEY-----"
+)
+
+func TestScanSecrets(t *testing.T) {
+	tests := []struct {
+		name         string
+		files        map[string]string
+		falseSecrets []string
+		want         []string
+	}{
+		{
+			name: "clean package",
+			files: map[string]string{
+				"lib/a.dart": "const apiUrl = 'https://example.com';\n",
+			},
+		},
+		{
+			name: "detects key formats with locations",
+			files: map[string]string{
+				"lib/config.dart": "// config\nconst aws = '" + fakeAWSKey + "';\nconst gh = '" + fakeGitHubPAT + "';\n",
+				"lib/keys.dart":   "const google = '" + fakeGoogleKey + "';\n",
+				"tool/key.pem":    fakePEMHeader + "\nMIIE...\n",
+				"bin/slack.dart":  "final token = '" + fakeSlackTok + "';\nfinal stripe = '" + fakeStripeKey + "';\n",
+			},
+			want: []string{
+				"lib/config.dart:2:aws-access-key",
+				"lib/config.dart:3:github-token",
+				"lib/keys.dart:1:gcp-api-key",
+				"tool/key.pem:1:private-key",
+				"bin/slack.dart:1:slack-token",
+				"bin/slack.dart:2:stripe-key",
+			},
+		},
+		{
+			name: "service account json",
+			files: map[string]string{
+				"assets/sa.json": "{\n  \"type\": \"service_account\",\n  \"project_id\": \"x\"\n}\n",
+			},
+			want: []string{"assets/sa.json:2:gcp-service-account"},
+		},
+		{
+			name: "false_secrets are skipped",
+			files: map[string]string{
+				"test/fixtures/key.pem": fakePEMHeader + "\n",
+				"lib/sample.dart":       "const k = '" + fakeAWSKey + "';\n",
+				"lib/real.dart":         "const k = '" + fakeAWSKey + "';\n",
+			},
+			falseSecrets: []string{"/test/fixtures/", "/lib/sample.dart"},
+			want:         []string{"lib/real.dart:1:aws-access-key"},
+		},
+		{
+			name: "lines longer than a scanner buffer",
+			files: map[string]string{
+				"web/app.min.js": "var a=1;\n" + strings.Repeat("x=1;", 1<<19) + "k='" + fakeAWSKey + "';\n",
+			},
+			want: []string{"web/app.min.js:2:aws-access-key"},
+		},
+		{
+			name: "binary files are skipped",
+			files: map[string]string{
+				"assets/blob.bin": "\x00\x01" + fakeAWSKey,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writePackageFiles(t, dir, tt.files)
+
+			files, err := ListPackageFiles(dir, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			findings, err := ScanSecrets(dir, files, tt.falseSecrets)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got := make(map[string]bool)
+			for _, f := range findings {
+				got[fmt.Sprintf("%s:%d:%s", f.File, f.Line, f.Rule)] = true
+				if strings.Contains(f.String(), fakeAWSKey) || strings.Contains(f.String(), fakeGitHubPAT) {
+					t.Errorf("finding %q is not redacted", f.String())
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("got %d findings %v, want %v", len(got), findings, tt.want)
+			}
+			for _, w := range tt.want {
+				if !got[w] {
+					t.Errorf("missing finding %s in %v", w, findings)
+				}
+			}
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	if got := redact(fakeAWSKey); got != "AKIAIOSF..." {
+		t.Errorf("redact() = %q", got)
+	}
+	if got := redact(fakePEMHeader); got != fakePEMHeader {
+		t.Errorf("redact() = %q, want header kept", got)
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// StateDir is the package-relative directory where the plugin keeps state
+// between hooks.
+const StateDir = ".dart_tool/relicta_pub"
+
+// snapshotFile is the name of the rollback snapshot inside StateDir.
+const snapshotFile = "snapshot.json"
+
+// Snapshot records the original contents of files the plugin modifies so
+// they can be restored if the release fails. It is persisted after every
+// change so that a later hook, possibly in another process, can restore it.
+// A nil Snapshot records nothing.
+type Snapshot struct {
+	// Dir is the package directory; file paths are relative to it.
+	Dir string `json:"-"`
+	// Version is the release the snapshot belongs to.
+	Version string         `json:"version"`
+	Files   []SnapshotFile `json:"files"`
+	// Commit is the release commit created by the plugin, if any.
+	Commit string `json:"commit,omitempty"`
+
+	path string
+}
+
+// SnapshotFile is the original state of one file.
+type SnapshotFile struct {
+	Path    string      `json:"path"`
+	Existed bool        `json:"existed"`
+	Mode    fs.FileMode `json:"mode,omitempty"`
+	Content []byte      `json:"content,omitempty"`
+}
+
+// OpenSnapshot returns the snapshot for the release of version, continuing
+// one started by an earlier hook of the same release. A snapshot left behind
+// by another release is replaced.
+func OpenSnapshot(dir, version string) (*Snapshot, error) {
+	s, err := LoadSnapshot(dir)
+	if err != nil {
+		return nil, err
+	}
+	if s != nil && s.Version == version {
+		return s, nil
+	}
+
+	s = &Snapshot{Dir: dir, Version: version, path: snapshotPath(dir)}
+	if err := s.Discard(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// LoadSnapshot loads the snapshot for the package in dir. It returns nil if
+// there is none.
+func LoadSnapshot(dir string) (*Snapshot, error) {
+	path := snapshotPath(dir)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %s: %w", path, err)
+	}
+	s.Dir = dir
+	s.path = path
+
+	return &s, nil
+}
+
+func snapshotPath(dir string) string {
+	return filepath.Join(dir, filepath.FromSlash(StateDir), snapshotFile)
+}
+
+// Capture records the current state of the package-relative file before the
+// plugin changes it. Only the first capture of a file is kept.
+func (s *Snapshot) Capture(file string) error {
+	if s == nil {
+		return nil
+	}
+	for _, f := range s.Files {
+		if f.Path == file {
+			return nil
+		}
+	}
+
+	entry := SnapshotFile{Path: file}
+	full := filepath.Join(s.Dir, file)
+	info, err := os.Stat(full)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return fmt.Errorf("failed to snapshot %s: %w", file, err)
+	default:
+		content, err := os.ReadFile(full)
+		if err != nil {
+			return fmt.Errorf("failed to snapshot %s: %w", file, err)
+		}
+		entry.Existed = true
+		entry.Mode = info.Mode().Perm()
+		entry.Content = content
+	}
+
+	s.Files = append(s.Files, entry)
+	return s.save()
+}
+
+// RecordCommit records a commit created by the plugin so Restore can undo it.
+func (s *Snapshot) RecordCommit(sha string) error {
+	if s == nil {
+		return nil
+	}
+	s.Commit = sha
+	return s.save()
+}
+
+// ReleaseCommit returns the release commit recorded in the snapshot.
+func (s *Snapshot) ReleaseCommit() string {
+	if s == nil {
+		return ""
+	}
+	return s.Commit
+}
+
+// Restore undoes the recorded changes and removes the snapshot. The release
+// commit is only undone while it is still HEAD. It returns a description of
+// each change that was reverted.
+func (s *Snapshot) Restore(ctx context.Context) ([]string, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	var restored []string
+	if s.Commit != "" {
+		git := NewGitCLI(s.Dir)
+		head, err := git.Head(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if head == s.Commit {
+			if err := git.Reset(ctx, s.Commit+"~1"); err != nil {
+				return nil, err
+			}
+			restored = append(restored, fmt.Sprintf("commit %s", shortSHA(s.Commit)))
+		}
+	}
+
+	for _, f := range s.Files {
+		full := filepath.Join(s.Dir, f.Path)
+		if !f.Existed {
+			if err := os.Remove(full); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return restored, fmt.Errorf("failed to remove %s: %w", f.Path, err)
+			}
+		} else if err := os.WriteFile(full, f.Content, f.Mode); err != nil {
+			return restored, fmt.Errorf("failed to restore %s: %w", f.Path, err)
+		}
+		restored = append(restored, f.Path)
+	}
+
+	return restored, s.Discard()
+}
+
+// Discard removes the persisted snapshot.
+func (s *Snapshot) Discard() error {
+	if s == nil {
+		return nil
+	}
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove snapshot: %w", err)
+	}
+	return nil
+}
+
+func (s *Snapshot) save() error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
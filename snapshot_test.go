@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSnapshot_CaptureRestore(t *testing.T) {
+	dir := t.TempDir()
+	writePackageFiles(t, dir, map[string]string{
+		"pubspec.yaml": "version: 1.0.0\n",
+	})
+
+	s, err := OpenSnapshot(dir, "1.1.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, f := range []string{"pubspec.yaml", "CHANGELOG.md"} {
+		if err := s.Capture(f); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	writePackageFiles(t, dir, map[string]string{
+		"pubspec.yaml": "version: 1.1.0\n",
+		"CHANGELOG.md": "## 1.1.0\n",
+	})
+	// A second capture must not overwrite the original content
+	if err := s.Capture("pubspec.yaml"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	loaded, err := LoadSnapshot(dir)
+	if err != nil || loaded == nil {
+		t.Fatalf("expected persisted snapshot, got %v, %v", loaded, err)
+	}
+	restored, err := loaded.Restore(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(restored, ",") != "pubspec.yaml,CHANGELOG.md" {
+		t.Errorf("unexpected restored files: %v", restored)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, "pubspec.yaml"))
+	if string(data) != "version: 1.0.0\n" {
+		t.Errorf("pubspec not restored: %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "CHANGELOG.md")); !os.IsNotExist(err) {
+		t.Errorf("expected CHANGELOG.md to be removed, got err %v", err)
+	}
+
+	if s, err := LoadSnapshot(dir); err != nil || s != nil {
+		t.Errorf("expected snapshot to be removed after restore, got %v, %v", s, err)
+	}
+}
+
+func TestSnapshot_RestoreCommit(t *testing.T) {
+	root := t.TempDir()
+	initGitRepo(t, root, map[string]string{
+		".gitignore":   ".dart_tool/\n",
+		"pubspec.yaml": "version: 1.0.0\n",
+	})
+	ctx := context.Background()
+
+	bump := func(t *testing.T) *Snapshot {
+		t.Helper()
+		s, err := OpenSnapshot(root, "1.1.0")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := s.Capture("pubspec.yaml"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		writePackageFiles(t, root, map[string]string{"pubspec.yaml": "version: 1.1.0\n"})
+		sha, err := CommitReleaseFiles(ctx, NewGitCLI(root), []string{"pubspec.yaml"}, "release 1.1.0")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := s.RecordCommit(sha); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return s
+	}
+
+	t.Run("release commit is HEAD", func(t *testing.T) {
+		s := bump(t)
+		restored, err := s.Restore(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(restored) != 2 || !strings.HasPrefix(restored[0], "commit ") {
+			t.Errorf("unexpected restored changes: %v", restored)
+		}
+		if log := gitCmd(t, root, "log", "--format=%s"); strings.Contains(log, "release 1.1.0") {
+			t.Errorf("expected release commit to be undone, log:\n%s", log)
+		}
+		if status := gitCmd(t, root, "status", "--porcelain"); status != "" {
+			t.Errorf("expected clean tree, got %q", status)
+		}
+	})
+
+	t.Run("commits on top are kept", func(t *testing.T) {
+		s := bump(t)
+		gitCmd(t, root, "commit", "-q", "--allow-empty", "-m", "later")
+		restored, err := s.Restore(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if strings.Join(restored, ",") != "pubspec.yaml" {
+			t.Errorf("unexpected restored changes: %v", restored)
+		}
+		if log := gitCmd(t, root, "log", "--format=%s"); !strings.Contains(log, "release 1.1.0") {
+			t.Errorf("expected release commit to be kept, log:\n%s", log)
+		}
+	})
+}
+
+func TestSnapshot_Nil(t *testing.T) {
+	var s *Snapshot
+	if err := s.Capture("pubspec.yaml"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := s.RecordCommit("abc"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if restored, err := s.Restore(context.Background()); err != nil || restored != nil {
+		t.Errorf("unexpected restore result: %v, %v", restored, err)
+	}
+}
+
+func TestOpenSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	writePackageFiles(t, dir, map[string]string{"pubspec.yaml": "version: 1.0.0\n"})
+
+	s, err := OpenSnapshot(dir, "1.1.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.Capture("pubspec.yaml"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A later hook of the same release continues the snapshot
+	s, err = OpenSnapshot(dir, "1.1.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(s.Files) != 1 {
+		t.Errorf("expected snapshot to be continued, got %+v", s.Files)
+	}
+
+	// Another release starts over
+	s, err = OpenSnapshot(dir, "1.2.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(s.Files) != 0 {
+		t.Errorf("expected a new snapshot, got %+v", s.Files)
+	}
+	if loaded, _ := LoadSnapshot(dir); loaded != nil {
+		t.Errorf("expected stale snapshot to be removed, got %+v", loaded)
+	}
+}
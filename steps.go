@@ -0,0 +1,694 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/helpers"
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+// StepConfig declares one step of the release pipeline. Built-in steps are
+// referenced by name; custom steps also set Run to a shell command.
+type StepConfig struct {
+	Name            string `json:"name"`
+	Run             string `json:"run"`
+	ContinueOnError bool   `json:"continue_on_error"`
+	// Parallel lets a custom step run alongside neighbouring independent steps.
+	Parallel bool `json:"parallel"`
+}
+
+// StepStatus is the outcome of a pipeline step.
+type StepStatus string
+
+// Step outcomes.
+const (
+	StepPassed   StepStatus = "passed"
+	StepFailed   StepStatus = "failed"
+	StepSkipped  StepStatus = "skipped"
+	StepCanceled StepStatus = "canceled"
+)
+
+// DefaultParallelism is the default number of independent steps run at once.
+const DefaultParallelism = 4
+
+// stepOutputLines is how much of a failed step's output the response shows.
+const stepOutputLines = 40
+
+// StepResult records the outcome of one pipeline step.
+type StepResult struct {
+	Name     string
+	Status   StepStatus
+	Duration time.Duration
+	Error    string
+	// Output is the combined stdout and stderr of the commands the step ran.
+	Output string
+	// Notes are the summaries the step adds to the response.
+	Notes []string
+	// ContinueOnError marks failures that did not stop the pipeline.
+	ContinueOnError bool
+
+	// interrupted records that the step's context was already canceled
+	// when it finished.
+	interrupted bool
+}
+
+// builtinStep is a step implemented by the plugin. Its name matches the
+// config key that enables it. Independent steps may run concurrently with
+// each other. Steps that list or read the files to upload are not
+// independent, since format_check and test write to the package.
+type builtinStep struct {
+	name        string
+	flag        func(cfg *Config) *bool
+	run         func(r *releaseRun, ctx context.Context) error
+	independent bool
+}
+
+// builtinSteps lists the built-in steps in their default order.
+var builtinSteps = []builtinStep{
+	{"pub_get", func(c *Config) *bool { return &c.PubGet }, (*releaseRun).pubGet, false},
+	{"outdated", func(c *Config) *bool { return &c.Outdated }, (*releaseRun).outdated, true},
+	{"check_downgrade", func(c *Config) *bool { return &c.CheckDowngrade }, (*releaseRun).checkDowngrade, true},
+	{"pana", func(c *Config) *bool { return &c.Pana }, (*releaseRun).pana, true},
+	{"update_version", func(c *Config) *bool { return &c.UpdateVersion }, (*releaseRun).updateVersion, false},
+	{"changelog", func(c *Config) *bool { return &c.Changelog }, (*releaseRun).changelog, false},
+	{"analyze", func(c *Config) *bool { return &c.Analyze }, (*releaseRun).analyze, true},
+	{"format_check", func(c *Config) *bool { return &c.FormatCheck }, (*releaseRun).formatCheck, true},
+	{"test", func(c *Config) *bool { return &c.Test }, (*releaseRun).test, true},
+	{"check_archive", func(c *Config) *bool { return &c.CheckArchive }, (*releaseRun).checkArchive, false},
+	{"scan_secrets", func(c *Config) *bool { return &c.ScanSecrets }, (*releaseRun).scanSecrets, false},
+	{"check_git", func(c *Config) *bool { return &c.CheckGit }, (*releaseRun).checkGit, false},
+	{"git_commit", func(c *Config) *bool { return &c.GitCommit }, (*releaseRun).gitCommit, false},
+	{"dry_run_validate", func(c *Config) *bool { return &c.DryRunValidate }, (*releaseRun).dryRunValidate, false},
+}
+
+// independent reports whether the step may run concurrently with
+// neighbouring independent steps.
+func (s StepConfig) independent() bool {
+	if s.Run != "" {
+		return s.Parallel
+	}
+	builtin, ok := findBuiltinStep(s.Name)
+	return ok && builtin.independent
+}
+
+func findBuiltinStep(name string) (builtinStep, bool) {
+	for _, s := range builtinSteps {
+		if s.name == name {
+			return s, true
+		}
+	}
+	return builtinStep{}, false
+}
+
+// parseSteps parses the steps list. Entries are either a built-in step name
+// or a map with name, run and continue_on_error.
+func parseSteps(raw any) []StepConfig {
+	items, ok := raw.([]any)
+	if !ok {
+		return nil
+	}
+
+	var steps []StepConfig
+	for _, item := range items {
+		switch v := item.(type) {
+		case string:
+			steps = append(steps, StepConfig{Name: v})
+		case map[string]any:
+			stepParser := helpers.NewConfigParser(v)
+			steps = append(steps, StepConfig{
+				Name:            stepParser.GetString("name", "", ""),
+				Run:             stepParser.GetString("run", "", ""),
+				ContinueOnError: stepParser.GetBool("continue_on_error", false),
+				Parallel:        stepParser.GetBool("parallel", false),
+			})
+		}
+	}
+	return steps
+}
+
+// applySteps makes an explicit steps list the source of truth for which
+// built-in steps are enabled.
+func applySteps(cfg *Config) {
+	if len(cfg.Steps) == 0 {
+		return
+	}
+	listed := make(map[string]bool, len(cfg.Steps))
+	for _, s := range cfg.Steps {
+		if s.Run == "" {
+			listed[s.Name] = true
+		}
+	}
+	for _, s := range builtinSteps {
+		*s.flag(cfg) = listed[s.name]
+	}
+}
+
+// validateSteps checks that every step is a known built-in or a named
+// custom command, and that no name is used twice.
+func validateSteps(vb *helpers.ValidationBuilder, steps []StepConfig) {
+	seen := make(map[string]bool, len(steps))
+	for i, s := range steps {
+		field := fmt.Sprintf("steps[%d]", i)
+		_, builtin := findBuiltinStep(s.Name)
+		switch {
+		case s.Name == "":
+			vb.AddError(field, fmt.Sprintf("%s: name is required", field))
+		case s.Run == "" && !builtin:
+			vb.AddError(field, fmt.Sprintf("%s: unknown step %q; custom steps need a run command", field, s.Name))
+		case s.Run != "" && builtin:
+			vb.AddError(field, fmt.Sprintf("%s: custom step %q conflicts with a built-in step", field, s.Name))
+		case seen[s.Name]:
+			vb.AddError(field, fmt.Sprintf("%s: duplicate step %q", field, s.Name))
+		}
+		seen[s.Name] = true
+	}
+}
+
+// pipelineSteps returns the steps to run in hook, in order. Without an
+// explicit steps list, the enabled built-in steps run in their default order.
+func pipelineSteps(cfg *Config, hook plugin.Hook) []StepConfig {
+	steps := cfg.Steps
+	if len(steps) == 0 {
+		for _, s := range builtinSteps {
+			if *s.flag(cfg) {
+				steps = append(steps, StepConfig{Name: s.name})
+			}
+		}
+	}
+
+	var selected []StepConfig
+	for _, s := range steps {
+		if stepHook(cfg, s) == hook {
+			selected = append(selected, s)
+		}
+	}
+	return selected
+}
+
+// stepHook returns the hook a step runs in. Only the steps that change
+// release files can move out of PrePublish.
+func stepHook(cfg *Config, step StepConfig) plugin.Hook {
+	if step.Run != "" {
+		return plugin.HookPrePublish
+	}
+	switch step.Name {
+	case "update_version":
+		return plugin.Hook(cfg.StepHooks.UpdateVersion)
+	case "changelog":
+		return plugin.Hook(cfg.StepHooks.Changelog)
+	case "git_commit":
+		return plugin.Hook(cfg.StepHooks.GitCommit)
+	default:
+		return plugin.HookPrePublish
+	}
+}
+
+// runPipeline runs the steps assigned to hook in order, running consecutive
+// independent steps concurrently on up to cfg.Parallelism workers. It stops
+// at the first failing step unless that step continues on error, canceling
+// the steps running alongside it and skipping the rest. It returns every
+// result, in pipeline order, and the fatal failure, if any.
+func (r *releaseRun) runPipeline(ctx context.Context, hook plugin.Hook) ([]StepResult, *StepResult) {
+	ctx, cancel := withDeadline(ctx, r.cfg)
+	defer cancel()
+
+	steps := pipelineSteps(r.cfg, hook)
+	results := make([]StepResult, 0, len(steps))
+
+	var fatal *StepResult
+	for _, batch := range stepBatches(steps) {
+		if fatal != nil {
+			for _, step := range batch {
+				results = append(results, StepResult{Name: step.Name, Status: StepSkipped})
+			}
+			continue
+		}
+
+		batchResults, failed := r.runBatch(ctx, batch)
+		results = append(results, batchResults...)
+		if failed >= 0 {
+			fatal = &results[len(results)-len(batch)+failed]
+		}
+	}
+
+	return results, fatal
+}
+
+// stepBatches groups consecutive independent steps so they can run
+// concurrently. Every other step runs in a batch of its own.
+func stepBatches(steps []StepConfig) [][]StepConfig {
+	var batches [][]StepConfig
+	for i, step := range steps {
+		n := len(batches)
+		if i > 0 && step.independent() && steps[i-1].independent() {
+			batches[n-1] = append(batches[n-1], step)
+			continue
+		}
+		batches = append(batches, []StepConfig{step})
+	}
+	return batches
+}
+
+// runBatch runs a batch of steps on a bounded worker pool. The first fatal
+// failure cancels the other steps of the batch. It returns the results in
+// batch order and the index of the fatal failure, or -1.
+func (r *releaseRun) runBatch(ctx context.Context, batch []StepConfig) ([]StepResult, int) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]StepResult, len(batch))
+	fatal := -1
+	var mu sync.Mutex
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(max(r.cfg.Parallelism, 1), len(batch)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if ctx.Err() != nil {
+					results[i] = StepResult{Name: batch[i].Name, Status: StepSkipped}
+					continue
+				}
+				result := r.runStep(ctx, batch[i])
+
+				mu.Lock()
+				if result.Status == StepFailed {
+					switch {
+					case result.interrupted && fatal >= 0:
+						// The step was still running when another step's failure canceled it
+						result.Status = StepCanceled
+					case !result.ContinueOnError && fatal < 0:
+						fatal = i
+						cancel()
+					}
+				}
+				mu.Unlock()
+				results[i] = result
+			}
+		}()
+	}
+
+	for i := range batch {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results, fatal
+}
+
+// runStep runs one step with its own logger, notes and output buffer so
+// concurrent steps do not interleave, enforcing the step's timeout.
+func (r *releaseRun) runStep(ctx context.Context, step StepConfig) StepResult {
+	var output syncBuffer
+	sr := *r
+	sr.logger = r.logger.With("step", step.Name)
+	sr.notes = nil
+	dart := *r.dart
+	dart.SetOutput(&output)
+	sr.dart = &dart
+
+	timeout := r.cfg.timeoutFor(step.Name)
+	stepCtx, cancel := withTimeout(ctx, timeout, fmt.Errorf("timed out after %s", timeout))
+	defer cancel()
+
+	start := time.Now()
+	err := timeoutCause(stepCtx, sr.execStep(stepCtx, step))
+	interrupted := ctx.Err() != nil
+	result := StepResult{
+		Name:            step.Name,
+		Status:          StepPassed,
+		Duration:        time.Since(start),
+		Output:          output.String(),
+		Notes:           sr.notes,
+		ContinueOnError: step.ContinueOnError,
+		interrupted:     interrupted,
+	}
+	if err != nil {
+		result.Status = StepFailed
+		result.Error = err.Error()
+		sr.logger.Error("Step failed", "error", err)
+	}
+	return result
+}
+
+func (r *releaseRun) execStep(ctx context.Context, step StepConfig) error {
+	if step.Run != "" {
+		return r.custom(ctx, step)
+	}
+	builtin, ok := findBuiltinStep(step.Name)
+	if !ok {
+		return fmt.Errorf("unknown step %q", step.Name)
+	}
+	return builtin.run(r, ctx)
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent writes from a command's
+// stdout and stderr.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// pipelineResponse builds the hook response from the step results.
+func (r *releaseRun) pipelineResponse(hook plugin.Hook, results []StepResult, fatal *StepResult) *plugin.ExecuteResponse {
+	report := FormatStepReport(results)
+	outputs := r.outputs(results)
+	if fatal != nil {
+		msg := fmt.Sprintf("Step %s failed: %s", fatal.Name, fatal.Error)
+		if output := tailLines(fatal.Output, stepOutputLines); output != "" {
+			msg = fmt.Sprintf("%s\n\nOutput of %s:\n%s", msg, fatal.Name, output)
+		}
+		return &plugin.ExecuteResponse{
+			Success: false,
+			Message: fmt.Sprintf("%s\n\n%s", msg, report),
+			Outputs: outputs,
+		}
+	}
+
+	msg := fmt.Sprintf("Completed %s steps", hook)
+	if hook == plugin.HookPrePublish {
+		msg = "Package validated successfully"
+	}
+
+	var notes []string
+	var failed int
+	for _, res := range results {
+		notes = append(notes, res.Notes...)
+		if res.Status == StepFailed {
+			failed++
+			notes = append(notes, fmt.Sprintf("warning: step %s failed: %s", res.Name, res.Error))
+		}
+	}
+	if failed > 0 {
+		msg = fmt.Sprintf("%s with %d non-blocking failure(s)", msg, failed)
+	}
+	if len(notes) > 0 {
+		msg = fmt.Sprintf("%s\n\n%s", msg, strings.Join(notes, "\n"))
+	}
+	if len(results) > 0 {
+		msg = fmt.Sprintf("%s\n\n%s", msg, report)
+	}
+
+	return &plugin.ExecuteResponse{
+		Success: true,
+		Message: msg,
+		Outputs: outputs,
+	}
+}
+
+// FormatStepReport renders the step results as an aligned table.
+func FormatStepReport(results []StepResult) string {
+	width := 0
+	for _, res := range results {
+		width = max(width, len(res.Name))
+	}
+
+	var sb strings.Builder
+	for _, res := range results {
+		line := fmt.Sprintf("%-*s  %-8s", width, res.Name, res.Status)
+		if res.Status != StepSkipped {
+			line += fmt.Sprintf("  %s", res.Duration.Round(time.Millisecond))
+		}
+		if res.Status == StepFailed && res.ContinueOnError {
+			line += "  (continued)"
+		}
+		sb.WriteString(strings.TrimRight(line, " "))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// tailLines returns the last n lines of s.
+func tailLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = append([]string{fmt.Sprintf("... (%d lines omitted)", len(lines)-n)}, lines[len(lines)-n:]...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// custom runs a user-defined shell command step.
+func (r *releaseRun) custom(ctx context.Context, step StepConfig) error {
+	r.logger.Info("Running custom step", "step", step.Name, "command", step.Run)
+	if r.cfg.DryRun {
+		r.logger.Info("[DRY-RUN] Would run custom step", "step", step.Name, "command", step.Run)
+		return nil
+	}
+	return r.dart.Shell(ctx, step.Run)
+}
+
+// pubGet resolves dependencies so analyze and test have a .dart_tool directory.
+func (r *releaseRun) pubGet(ctx context.Context) error {
+	r.logger.Info("Resolving dependencies")
+	if r.cfg.DryRun {
+		r.logger.Info("[DRY-RUN] Would run pub get", "config", r.cfg.PubGetConfig, "flutter", r.isFlutter)
+		return nil
+	}
+
+	if err := r.snapshot.Capture("pubspec.lock"); err != nil {
+		return fmt.Errorf("failed to prepare rollback: %w", err)
+	}
+	if r.isFlutter {
+		return r.dart.FlutterGetDependencies(ctx, r.cfg.PubGetConfig)
+	}
+	return r.dart.GetDependencies(ctx, r.cfg.PubGetConfig)
+}
+
+// outdated checks dependency health with pub outdated.
+func (r *releaseRun) outdated(ctx context.Context) error {
+	r.logger.Info("Checking dependency health")
+	if r.cfg.DryRun {
+		r.logger.Info("[DRY-RUN] Would run pub outdated", "config", r.cfg.OutdatedConfig, "flutter", r.isFlutter)
+		return nil
+	}
+
+	var report *OutdatedReport
+	var err error
+	if r.isFlutter {
+		report, err = r.dart.FlutterOutdated(ctx)
+	} else {
+		report, err = r.dart.Outdated(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	table := FormatOutdatedTable(report)
+	var failures []string
+	for _, f := range EvaluateOutdated(report, r.cfg.OutdatedConfig) {
+		if f.Policy == PolicyFail {
+			failures = append(failures, f.Message)
+		} else {
+			r.logger.Warn("Dependency health warning", "package", f.Package, "message", f.Message)
+			r.notes = append(r.notes, fmt.Sprintf("warning: %s", f.Message))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%s\n\n%s", strings.Join(failures, "; "), table)
+	}
+	r.notes = append(r.notes, table)
+	return nil
+}
+
+// checkDowngrade verifies lower constraint bounds in a scratch copy.
+func (r *releaseRun) checkDowngrade(ctx context.Context) error {
+	r.logger.Info("Checking downgraded dependency resolution")
+	if r.cfg.DryRun {
+		r.logger.Info("[DRY-RUN] Would run pub downgrade check", "config", r.cfg.DowngradeConfig, "flutter", r.isFlutter)
+		return nil
+	}
+	return RunDowngradeCheck(ctx, r.packageDir, r.isFlutter, r.cfg.DowngradeConfig, r.cfg.TestConfig)
+}
+
+// pana previews the pub score.
+func (r *releaseRun) pana(ctx context.Context) error {
+	r.logger.Info("Running pana")
+	if r.cfg.DryRun {
+		r.logger.Info("[DRY-RUN] Would run pana", "config", r.cfg.PanaConfig)
+		return nil
+	}
+
+	report, err := r.dart.Pana(ctx, r.cfg.PanaConfig.Executable)
+	if err != nil {
+		return err
+	}
+
+	breakdown := FormatPanaBreakdown(report)
+	if failures := EvaluatePana(report, r.cfg.PanaConfig); len(failures) > 0 {
+		return fmt.Errorf("%s\n\n%s", strings.Join(failures, "; "), breakdown)
+	}
+	r.notes = append(r.notes, breakdown)
+	return nil
+}
+
+// analyze runs dart analyze.
+func (r *releaseRun) analyze(ctx context.Context) error {
+	r.logger.Info("Running dart analyze")
+	if r.cfg.DryRun {
+		r.logger.Info("[DRY-RUN] Would run dart analyze")
+		return nil
+	}
+	return r.dart.Analyze(ctx)
+}
+
+// formatCheck checks code formatting.
+func (r *releaseRun) formatCheck(ctx context.Context) error {
+	r.logger.Info("Checking code formatting")
+	if r.cfg.DryRun {
+		r.logger.Info("[DRY-RUN] Would check code formatting")
+		return nil
+	}
+	return r.dart.FormatCheck(ctx)
+}
+
+// test runs the Dart or Flutter tests.
+func (r *releaseRun) test(ctx context.Context) error {
+	r.logger.Info("Running tests")
+	if r.cfg.DryRun {
+		r.logger.Info("[DRY-RUN] Would run tests", "config", r.cfg.TestConfig, "flutter", r.isFlutter)
+		return nil
+	}
+	if r.isFlutter {
+		return r.dart.FlutterTest(ctx)
+	}
+	// Coverage from an earlier run would otherwise be summarized as this one's
+	if r.cfg.TestConfig.Coverage {
+		if err := os.RemoveAll(filepath.Join(r.packageDir, filepath.FromSlash(CoverageDir))); err != nil {
+			return fmt.Errorf("failed to clear stale coverage: %w", err)
+		}
+	}
+	return r.dart.Test(ctx, r.cfg.TestConfig)
+}
+
+// packageFileList lists the files that would be uploaded, and builds their
+// archive, once for all the steps that need them.
+type packageFileList struct {
+	once  sync.Once
+	files []PackageFile
+	err   error
+
+	archiveOnce sync.Once
+	archive     *ArchiveReport
+	archiveErr  error
+}
+
+// packageFiles lists the files that would be uploaded.
+func (r *releaseRun) packageFiles() ([]PackageFile, error) {
+	r.files.once.Do(func() {
+		r.files.files, r.files.err = ListPackageFiles(r.packageDir, r.cfg.Exclude)
+		if r.files.err != nil {
+			r.files.err = fmt.Errorf("failed to list package files: %w", r.files.err)
+		}
+	})
+	return r.files.files, r.files.err
+}
+
+// archiveReport builds the archive that would be uploaded.
+func (r *releaseRun) archiveReport() (*ArchiveReport, error) {
+	r.files.archiveOnce.Do(func() {
+		files, err := r.packageFiles()
+		if err != nil {
+			r.files.archiveErr = err
+			return
+		}
+		r.files.archive, r.files.archiveErr = BuildArchiveReport(r.packageDir, files)
+	})
+	return r.files.archive, r.files.archiveErr
+}
+
+// checkArchive inspects the package archive size and contents.
+func (r *releaseRun) checkArchive(_ context.Context) error {
+	r.logger.Info("Inspecting package archive")
+	report, err := r.archiveReport()
+	if err != nil {
+		return err
+	}
+
+	summary := FormatArchiveSummary(report, r.cfg.ArchiveConfig.Largest)
+	if failures := EvaluateArchive(report, r.cfg.ArchiveConfig); len(failures) > 0 {
+		return fmt.Errorf("%s\n\n%s", strings.Join(failures, "; "), summary)
+	}
+	r.notes = append(r.notes, summary)
+	return nil
+}
+
+// scanSecrets scans the files that would be uploaded for credentials.
+func (r *releaseRun) scanSecrets(_ context.Context) error {
+	r.logger.Info("Scanning package files for secrets")
+	files, err := r.packageFiles()
+	if err != nil {
+		return err
+	}
+	findings, err := ScanSecrets(r.packageDir, files, r.pubspec.FalseSecrets)
+	if err != nil {
+		return err
+	}
+	if len(findings) == 0 {
+		return nil
+	}
+
+	lines := make([]string, len(findings))
+	for i, f := range findings {
+		lines[i] = f.String()
+	}
+	return fmt.Errorf("found %d potential secret(s); remove them or list the files in false_secrets:\n%s",
+		len(findings), strings.Join(lines, "\n"))
+}
+
+// checkGit verifies the git working tree matches the release.
+func (r *releaseRun) checkGit(ctx context.Context) error {
+	r.logger.Info("Checking git repository state")
+	problems, err := CheckGitState(ctx, NewGitCLI(r.packageDir), GitCheck{
+		Config:     r.cfg.GitConfig,
+		Modified:   pluginModifiedFiles(r.pubspecPath, r.cfg),
+		CommitSHA:  r.release.CommitSHA,
+		TagName:    r.release.TagName,
+		Repository: r.pubspec.Repository,
+		// A release commit made in an earlier hook sits on top of the release commit
+		ReleaseCommit: r.snapshot.ReleaseCommit(),
+	})
+	if err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// dryRunValidate runs dart pub publish --dry-run.
+func (r *releaseRun) dryRunValidate(ctx context.Context) error {
+	if !r.pubspec.IsPublishable() {
+		r.logger.Info("Skipping publish dry-run validation: publish_to is none")
+		return nil
+	}
+
+	r.logger.Info("Running publish dry-run validation")
+	if r.cfg.DryRun {
+		r.logger.Info("[DRY-RUN] Would run dart pub publish --dry-run")
+		return nil
+	}
+	return r.dart.PublishDryRun(ctx)
+}
@@ -0,0 +1,559 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/helpers"
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+)
+
+func TestParseSteps(t *testing.T) {
+	raw := []any{
+		"analyze",
+		map[string]any{"name": "docs", "run": "dart doc", "continue_on_error": true},
+		42,
+	}
+
+	got := parseSteps(raw)
+	want := []StepConfig{
+		{Name: "analyze"},
+		{Name: "docs", Run: "dart doc", ContinueOnError: true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d steps, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("step %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+
+	if steps := parseSteps(nil); steps != nil {
+		t.Errorf("expected no steps, got %+v", steps)
+	}
+}
+
+func TestApplySteps(t *testing.T) {
+	p := &PubPlugin{}
+
+	cfg := p.parseConfig(map[string]any{
+		"steps": []any{"test", "scan_secrets", map[string]any{"name": "analyze", "run": "make lint"}},
+	})
+	if !cfg.Test || !cfg.ScanSecrets {
+		t.Error("expected listed built-in steps to be enabled")
+	}
+	if cfg.Analyze || cfg.PubGet || cfg.UpdateVersion || cfg.DryRunValidate {
+		t.Error("expected unlisted built-in steps to be disabled")
+	}
+
+	cfg = p.parseConfig(map[string]any{})
+	if !cfg.Analyze || !cfg.PubGet || cfg.ScanSecrets {
+		t.Error("expected default step flags without a steps list")
+	}
+}
+
+func TestValidateSteps(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   []StepConfig
+		wantErr string
+	}{
+		{
+			name:  "builtin and custom",
+			steps: []StepConfig{{Name: "analyze"}, {Name: "docs", Run: "dart doc"}},
+		},
+		{
+			name:    "missing name",
+			steps:   []StepConfig{{Run: "true"}},
+			wantErr: "name is required",
+		},
+		{
+			name:    "unknown step",
+			steps:   []StepConfig{{Name: "lint"}},
+			wantErr: `unknown step "lint"`,
+		},
+		{
+			name:    "custom shadows builtin",
+			steps:   []StepConfig{{Name: "test", Run: "make test"}},
+			wantErr: "conflicts with a built-in step",
+		},
+		{
+			name:    "duplicate",
+			steps:   []StepConfig{{Name: "analyze"}, {Name: "analyze"}},
+			wantErr: `duplicate step "analyze"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vb := helpers.NewValidationBuilder()
+			validateSteps(vb, tt.steps)
+			resp := vb.Build()
+
+			if tt.wantErr == "" {
+				if !resp.Valid {
+					t.Errorf("expected valid steps, got %+v", resp.Errors)
+				}
+				return
+			}
+			if resp.Valid {
+				t.Fatalf("expected error containing %q", tt.wantErr)
+			}
+			if !strings.Contains(resp.Errors[0].Message, tt.wantErr) {
+				t.Errorf("expected error containing %q, got %q", tt.wantErr, resp.Errors[0].Message)
+			}
+		})
+	}
+}
+
+func TestPipelineSteps(t *testing.T) {
+	p := &PubPlugin{}
+
+	names := func(steps []StepConfig) string {
+		var out []string
+		for _, s := range steps {
+			out = append(out, s.Name)
+		}
+		return strings.Join(out, ",")
+	}
+
+	// Default order follows the enabled flags
+	cfg := p.parseConfig(map[string]any{"scan_secrets": true})
+	if got := names(pipelineSteps(cfg, plugin.HookPrePublish)); got != "pub_get,update_version,analyze,format_check,test,scan_secrets,dry_run_validate" {
+		t.Errorf("unexpected default pipeline: %s", got)
+	}
+
+	// An explicit list keeps its order and moves file steps to their hooks
+	cfg = p.parseConfig(map[string]any{
+		"steps": []any{
+			"test",
+			map[string]any{"name": "docs", "run": "dart doc"},
+			"update_version",
+			"analyze",
+		},
+		"step_hooks": map[string]any{"update_version": "post-version"},
+	})
+	if got := names(pipelineSteps(cfg, plugin.HookPrePublish)); got != "test,docs,analyze" {
+		t.Errorf("unexpected pre-publish pipeline: %s", got)
+	}
+	if got := names(pipelineSteps(cfg, plugin.HookPostVersion)); got != "update_version" {
+		t.Errorf("unexpected post-version pipeline: %s", got)
+	}
+	if got := names(pipelineSteps(cfg, plugin.HookPostNotes)); got != "" {
+		t.Errorf("expected empty post-notes pipeline, got %s", got)
+	}
+}
+
+func TestStepBatches(t *testing.T) {
+	steps := []StepConfig{
+		{Name: "pub_get"},
+		{Name: "analyze"},
+		{Name: "format_check"},
+		{Name: "docs", Run: "dart doc", Parallel: true},
+		{Name: "lint", Run: "make lint"},
+		{Name: "test"},
+		{Name: "scan_secrets"},
+		{Name: "dry_run_validate"},
+	}
+
+	var got []string
+	for _, batch := range stepBatches(steps) {
+		var names []string
+		for _, s := range batch {
+			names = append(names, s.Name)
+		}
+		got = append(got, strings.Join(names, "+"))
+	}
+
+	want := "pub_get,analyze+format_check+docs,lint,test,scan_secrets,dry_run_validate"
+	if strings.Join(got, ",") != want {
+		t.Errorf("expected batches %s, got %s", want, strings.Join(got, ","))
+	}
+}
+
+// newTestRun returns a release run of test_package@1.1.0 in dir.
+func newTestRun(dir string, cfg *Config) *releaseRun {
+	return &releaseRun{
+		cfg:        cfg,
+		release:    &plugin.ReleaseContext{Version: "1.1.0"},
+		logger:     slog.New(slog.DiscardHandler),
+		packageDir: dir,
+		pubspec:    &Pubspec{Name: "test_package", Version: "1.0.0"},
+		dart:       NewDartCLI(dir),
+		files:      &packageFileList{},
+	}
+}
+
+func TestReleaseRun_RunPipeline(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("custom steps use POSIX shell commands")
+	}
+
+	tempDir := t.TempDir()
+	pubspecPath := filepath.Join(tempDir, "pubspec.yaml")
+	if err := os.WriteFile(pubspecPath, []byte("name: test_package\nversion: 1.0.0\n"), 0644); err != nil {
+		t.Fatalf("failed to create pubspec: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		steps      []any
+		wantStatus []StepStatus
+		wantFatal  string
+	}{
+		{
+			name: "all pass",
+			steps: []any{
+				map[string]any{"name": "one", "run": "true"},
+				map[string]any{"name": "two", "run": "touch marker"},
+			},
+			wantStatus: []StepStatus{StepPassed, StepPassed},
+		},
+		{
+			name: "stops at failure",
+			steps: []any{
+				map[string]any{"name": "one", "run": "echo broken >&2; exit 3"},
+				map[string]any{"name": "two", "run": "true"},
+			},
+			wantStatus: []StepStatus{StepFailed, StepSkipped},
+			wantFatal:  "one",
+		},
+		{
+			name: "continue on error",
+			steps: []any{
+				map[string]any{"name": "one", "run": "false", "continue_on_error": true},
+				map[string]any{"name": "two", "run": "true"},
+			},
+			wantStatus: []StepStatus{StepFailed, StepPassed},
+		},
+		{
+			name: "parallel failure cancels siblings",
+			steps: []any{
+				map[string]any{"name": "slow", "run": "sleep 10", "parallel": true},
+				map[string]any{"name": "broken", "run": "exit 1", "parallel": true},
+				map[string]any{"name": "after", "run": "true"},
+			},
+			wantStatus: []StepStatus{StepCanceled, StepFailed, StepSkipped},
+			wantFatal:  "broken",
+		},
+		{
+			name: "parallel success",
+			steps: []any{
+				map[string]any{"name": "one", "run": "sleep 0.2", "parallel": true},
+				map[string]any{"name": "two", "run": "sleep 0.2", "parallel": true},
+				map[string]any{"name": "three", "run": "sleep 0.2", "parallel": true},
+			},
+			wantStatus: []StepStatus{StepPassed, StepPassed, StepPassed},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := (&PubPlugin{}).parseConfig(map[string]any{
+				"pubspec_path": pubspecPath,
+				"steps":        tt.steps,
+			})
+			r := newTestRun(tempDir, cfg)
+
+			start := time.Now()
+			results, fatal := r.runPipeline(context.Background(), plugin.HookPrePublish)
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("expected parallel steps to finish early, took %s", elapsed)
+			}
+			if len(results) != len(tt.wantStatus) {
+				t.Fatalf("expected %d results, got %+v", len(tt.wantStatus), results)
+			}
+			for i, want := range tt.wantStatus {
+				if results[i].Status != want {
+					t.Errorf("step %s: expected %s, got %s", results[i].Name, want, results[i].Status)
+				}
+			}
+
+			switch {
+			case tt.wantFatal == "" && fatal != nil:
+				t.Errorf("unexpected fatal failure: %+v", fatal)
+			case tt.wantFatal != "" && (fatal == nil || fatal.Name != tt.wantFatal):
+				t.Errorf("expected fatal failure in %s, got %+v", tt.wantFatal, fatal)
+			}
+
+			resp := r.pipelineResponse(plugin.HookPrePublish, results, fatal)
+			if resp.Success != (fatal == nil) {
+				t.Errorf("expected success %v, got %+v", fatal == nil, resp)
+			}
+		})
+	}
+
+	if _, err := os.Stat(filepath.Join(tempDir, "marker")); err != nil {
+		t.Errorf("expected custom step to run in the package directory: %v", err)
+	}
+}
+
+func TestReleaseRun_RunPipeline_Output(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("custom steps use POSIX shell commands")
+	}
+
+	tempDir := t.TempDir()
+	cfg := (&PubPlugin{}).parseConfig(map[string]any{
+		"steps": []any{
+			map[string]any{"name": "one", "run": "echo from one; echo warn one >&2", "parallel": true},
+			map[string]any{"name": "two", "run": "echo from two; exit 1", "parallel": true},
+		},
+	})
+	r := newTestRun(tempDir, cfg)
+
+	results, fatal := r.runPipeline(context.Background(), plugin.HookPrePublish)
+	if fatal == nil || fatal.Name != "two" {
+		t.Fatalf("expected step two to fail, got %+v", results)
+	}
+	if got := results[1].Output; got != "from two\n" {
+		t.Errorf("unexpected output for two: %q", got)
+	}
+	if results[0].Status == StepPassed && !strings.Contains(results[0].Output, "from one") {
+		t.Errorf("expected output of one to be kept separately, got %q", results[0].Output)
+	}
+
+	resp := r.pipelineResponse(plugin.HookPrePublish, results, fatal)
+	if !strings.Contains(resp.Message, "Output of two:\nfrom two") {
+		t.Errorf("expected failed step output in message, got:\n%s", resp.Message)
+	}
+}
+
+func TestReleaseRun_RunPipeline_ArchiveAfterWriters(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("custom steps use POSIX shell commands")
+	}
+
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "pubspec.yaml"), []byte("name: test_package\nversion: 1.0.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := (&PubPlugin{}).parseConfig(map[string]any{
+		"parallelism": 4,
+		"steps": []any{
+			map[string]any{"name": "codegen", "run": "sleep 0.2; mkdir -p lib; echo gen > lib/gen.dart", "parallel": true},
+			"check_archive",
+			"scan_secrets",
+		},
+	})
+	r := newTestRun(tempDir, cfg)
+
+	results, fatal := r.runPipeline(context.Background(), plugin.HookPrePublish)
+	if fatal != nil {
+		t.Fatalf("unexpected failure: %+v", results)
+	}
+	if r.files.archive == nil {
+		t.Fatal("expected the archive to be built")
+	}
+	var paths []string
+	for _, f := range r.files.archive.Files {
+		paths = append(paths, f.Path)
+	}
+	if !slices.Contains(paths, "lib/gen.dart") {
+		t.Errorf("expected the archive to include the file written by the step before it, got %v", paths)
+	}
+}
+
+// blockingHandler holds the goroutine logging a record whose error matches
+// until release is closed, after signalling logged.
+type blockingHandler struct {
+	slog.Handler
+	match   string
+	logged  chan struct{}
+	release chan struct{}
+}
+
+func (h *blockingHandler) Handle(ctx context.Context, rec slog.Record) error {
+	rec.Attrs(func(a slog.Attr) bool {
+		if a.Key == "error" && strings.Contains(a.Value.String(), h.match) {
+			close(h.logged)
+			<-h.release
+			return false
+		}
+		return true
+	})
+	return nil
+}
+
+func (h *blockingHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *blockingHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func TestReleaseRun_RunBatch_KeepsGenuineFailures(t *testing.T) {
+	handler := &blockingHandler{Handler: slog.DiscardHandler, match: "early", logged: make(chan struct{}), release: make(chan struct{})}
+
+	// early fails on its own and is held in its failure log until the
+	// batch is canceled by fatal, which waits for early to finish and for
+	// running to start first
+	started := make(chan struct{})
+	saved := builtinSteps
+	t.Cleanup(func() { builtinSteps = saved })
+	builtinSteps = append(slices.Clone(builtinSteps),
+		builtinStep{"early", nil, func(*releaseRun, context.Context) error {
+			return errors.New("early failure")
+		}, true},
+		builtinStep{"fatal", nil, func(*releaseRun, context.Context) error {
+			<-handler.logged
+			<-started
+			return errors.New("fatal failure")
+		}, true},
+		builtinStep{"running", nil, func(_ *releaseRun, ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			close(handler.release)
+			return ctx.Err()
+		}, true},
+	)
+
+	r := newTestRun(t.TempDir(), &Config{Parallelism: 3})
+	r.logger = slog.New(handler)
+
+	results, fatal := r.runBatch(context.Background(), []StepConfig{{Name: "early"}, {Name: "fatal"}, {Name: "running"}})
+	if fatal != 1 {
+		t.Fatalf("expected fatal to stop the batch, got %d: %+v", fatal, results)
+	}
+	want := []StepStatus{StepFailed, StepFailed, StepCanceled}
+	for i, w := range want {
+		if results[i].Status != w {
+			t.Errorf("%s: expected %s, got %s", results[i].Name, w, results[i].Status)
+		}
+	}
+}
+
+func TestReleaseRun_RunPipeline_Timeouts(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("custom steps use POSIX shell commands")
+	}
+
+	tests := []struct {
+		name      string
+		config    map[string]any
+		wantError string
+	}{
+		{
+			name: "step timeout",
+			config: map[string]any{
+				"steps":    []any{map[string]any{"name": "hang", "run": "(sleep 1; touch late) & echo partial; wait"}},
+				"timeouts": map[string]any{"hang": "200ms"},
+			},
+			wantError: "timed out after 200ms",
+		},
+		{
+			name: "deadline",
+			config: map[string]any{
+				"steps": []any{
+					map[string]any{"name": "first", "run": "true"},
+					map[string]any{"name": "hang", "run": "(sleep 1; touch late) & echo partial; wait"},
+				},
+				"deadline": "300ms",
+			},
+			wantError: "deadline of 300ms exceeded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			r := newTestRun(tempDir, (&PubPlugin{}).parseConfig(tt.config))
+
+			start := time.Now()
+			results, fatal := r.runPipeline(context.Background(), plugin.HookPrePublish)
+			if elapsed := time.Since(start); elapsed > 900*time.Millisecond {
+				t.Errorf("expected the step to be stopped early, took %s", elapsed)
+			}
+			if fatal == nil || fatal.Name != "hang" || fatal.Error != tt.wantError {
+				t.Fatalf("expected hang to fail with %q, got %+v", tt.wantError, results)
+			}
+
+			resp := r.pipelineResponse(plugin.HookPrePublish, results, fatal)
+			if !strings.Contains(resp.Message, "Step hang failed: "+tt.wantError) || !strings.Contains(resp.Message, "Output of hang:\npartial") {
+				t.Errorf("expected timeout and partial output in message, got:\n%s", resp.Message)
+			}
+
+			// The whole process group is killed, including the background child
+			time.Sleep(1500 * time.Millisecond)
+			if _, err := os.Stat(filepath.Join(tempDir, "late")); err == nil {
+				t.Error("expected the child process to be killed with the step")
+			}
+		})
+	}
+}
+
+func TestReleaseRun_Test_ClearsStaleCoverage(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as a stand-in for dart")
+	}
+
+	// Stand in for a dart test run that writes no coverage
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "dart"), []byte("#!/bin/sh\necho \"$@\" > args.txt\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	dir := t.TempDir()
+	coverageDir := filepath.Join(dir, filepath.FromSlash(CoverageDir))
+	for path, content := range map[string]string{
+		filepath.Join(coverageDir, "stale.json"):    `{"coverage":[]}`,
+		filepath.Join(dir, "coverage", "lcov.info"): "SF:lib/a.dart\n",
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := newTestRun(dir, &Config{TestConfig: TestConfig{Coverage: true}})
+	if err := r.test(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if args, _ := os.ReadFile(filepath.Join(dir, "args.txt")); string(args) != "test --coverage="+CoverageDir+"\n" {
+		t.Errorf("unexpected dart arguments: %q", args)
+	}
+	if _, err := os.Stat(coverageDir); !os.IsNotExist(err) {
+		t.Errorf("expected stale coverage to be removed, got %v", err)
+	}
+	if coverage, err := ReadCoverage(coverageDir, "test_package"); coverage != nil || err != nil {
+		t.Errorf("expected no coverage summary, got %+v, %v", coverage, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "coverage", "lcov.info")); err != nil {
+		t.Errorf("expected the package's own coverage directory to be kept: %v", err)
+	}
+}
+
+func TestTailLines(t *testing.T) {
+	if got := tailLines("a\nb\n", 5); got != "a\nb" {
+		t.Errorf("unexpected tail: %q", got)
+	}
+	if got := tailLines("a\nb\nc\nd\n", 2); got != "... (2 lines omitted)\nc\nd" {
+		t.Errorf("unexpected tail: %q", got)
+	}
+}
+
+func TestFormatStepReport(t *testing.T) {
+	results := []StepResult{
+		{Name: "analyze", Status: StepPassed, Duration: 1200 * time.Millisecond},
+		{Name: "docs", Status: StepFailed, Duration: 30 * time.Millisecond, ContinueOnError: true},
+		{Name: "test", Status: StepFailed, Duration: 2 * time.Second},
+		{Name: "dry_run_validate", Status: StepSkipped},
+	}
+
+	want := "analyze           passed    1.2s\n" +
+		"docs              failed    30ms  (continued)\n" +
+		"test              failed    2s\n" +
+		"dry_run_validate  skipped\n"
+	if got := FormatStepReport(results); got != want {
+		t.Errorf("unexpected report:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+// TemplateData holds the release values available to configurable
+// templates such as commit messages.
+type TemplateData struct {
+	Package         string
+	Version         string
+	PreviousVersion string
+	Tag             string
+}
+
+// RenderTemplate executes a text/template against the release data.
+func RenderTemplate(text string, data TemplateData) (string, error) {
+	tmpl, err := template.New("").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid template %q: %w", text, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render template %q: %w", text, err)
+	}
+
+	return buf.String(), nil
+}
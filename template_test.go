@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestRenderTemplate(t *testing.T) {
+	data := TemplateData{Package: "my_package", Version: "1.2.0", PreviousVersion: "1.1.0", Tag: "v1.2.0"}
+
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{"plain", "release", "release", false},
+		{"fields", "chore(release): {{.Package}} {{.Version}} ({{.Tag}}, was {{.PreviousVersion}})",
+			"chore(release): my_package 1.2.0 (v1.2.0, was 1.1.0)", false},
+		{"parse error", "{{.Version", "", true},
+		{"unknown field", "{{.Branch}}", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderTemplate(tt.text, data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RenderTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("RenderTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
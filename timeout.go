@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/helpers"
+)
+
+// PublishTimeoutKey is the timeouts key that limits dart pub publish.
+const PublishTimeoutKey = "publish"
+
+// parseTimeouts parses the timeouts map of step names to durations.
+func parseTimeouts(raw any) map[string]string {
+	items, ok := raw.(map[string]any)
+	if !ok {
+		return nil
+	}
+	timeouts := make(map[string]string, len(items))
+	for name, v := range items {
+		timeouts[name] = fmt.Sprint(v)
+	}
+	return timeouts
+}
+
+// validateTimeouts checks that every timeout names a step or publish and
+// that all durations are positive.
+func validateTimeouts(vb *helpers.ValidationBuilder, cfg *Config) {
+	for name, value := range cfg.Timeouts {
+		field := "timeouts." + name
+		if !isTimeoutTarget(cfg, name) {
+			vb.AddError(field, fmt.Sprintf("%s: unknown step %q", field, name))
+		}
+		if !validDuration(value) {
+			vb.AddError(field, fmt.Sprintf("%s: invalid duration %q", field, value))
+		}
+	}
+	if cfg.Deadline != "" && !validDuration(cfg.Deadline) {
+		vb.AddError("deadline", fmt.Sprintf("deadline: invalid duration %q", cfg.Deadline))
+	}
+}
+
+func isTimeoutTarget(cfg *Config, name string) bool {
+	if _, ok := findBuiltinStep(name); ok || name == PublishTimeoutKey {
+		return true
+	}
+	for _, s := range cfg.Steps {
+		if s.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func validDuration(s string) bool {
+	d, err := time.ParseDuration(s)
+	return err == nil && d > 0
+}
+
+// timeoutFor returns the timeout configured for a step or publish, or 0 if
+// there is none.
+func (c *Config) timeoutFor(name string) time.Duration {
+	return parsePositiveDuration(c.Timeouts[name])
+}
+
+// deadline returns the limit on one hook's run, or 0 if there is none.
+func (c *Config) deadline() time.Duration {
+	return parsePositiveDuration(c.Deadline)
+}
+
+func parsePositiveDuration(s string) time.Duration {
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0
+	}
+	return d
+}
+
+// withTimeout returns a context that expires after d, recording cause as
+// the reason. A zero d only adds cancellation.
+func withTimeout(ctx context.Context, d time.Duration, cause error) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeoutCause(ctx, d, cause)
+}
+
+// withDeadline applies the configured deadline to a hook's run.
+func withDeadline(ctx context.Context, cfg *Config) (context.Context, context.CancelFunc) {
+	d := cfg.deadline()
+	return withTimeout(ctx, d, fmt.Errorf("deadline of %s exceeded", d))
+}
+
+// timeoutCause replaces err with the reason ctx expired, such as the step
+// timeout or the deadline, so the report says what ran out of time.
+func timeoutCause(ctx context.Context, err error) error {
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return context.Cause(ctx)
+	}
+	return err
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/helpers"
+)
+
+func TestPubPlugin_ParseConfig_Timeouts(t *testing.T) {
+	p := &PubPlugin{}
+
+	cfg := p.parseConfig(map[string]any{
+		"timeouts": map[string]any{"test": "30m", "publish": "5m"},
+		"deadline": "1h",
+	})
+	if got := cfg.timeoutFor("test"); got != 30*time.Minute {
+		t.Errorf("expected test timeout 30m, got %s", got)
+	}
+	if got := cfg.timeoutFor(PublishTimeoutKey); got != 5*time.Minute {
+		t.Errorf("expected publish timeout 5m, got %s", got)
+	}
+	if got := cfg.timeoutFor("analyze"); got != 0 {
+		t.Errorf("expected no analyze timeout, got %s", got)
+	}
+	if got := cfg.deadline(); got != time.Hour {
+		t.Errorf("expected deadline 1h, got %s", got)
+	}
+
+	if cfg := p.parseConfig(map[string]any{}); cfg.deadline() != 0 || len(cfg.Timeouts) != 0 {
+		t.Errorf("expected no timeouts by default, got %+v %q", cfg.Timeouts, cfg.Deadline)
+	}
+}
+
+func TestValidateTimeouts(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       Config
+		wantField string
+	}{
+		{
+			name: "valid",
+			cfg: Config{
+				Timeouts: map[string]string{"test": "10m", "publish": "2m", "docs": "30s"},
+				Steps:    []StepConfig{{Name: "docs", Run: "dart doc"}},
+				Deadline: "1h",
+			},
+		},
+		{
+			name:      "unknown step",
+			cfg:       Config{Timeouts: map[string]string{"lint": "1m"}},
+			wantField: "timeouts.lint",
+		},
+		{
+			name:      "invalid duration",
+			cfg:       Config{Timeouts: map[string]string{"test": "forever"}},
+			wantField: "timeouts.test",
+		},
+		{
+			name:      "negative duration",
+			cfg:       Config{Timeouts: map[string]string{"test": "-1m"}},
+			wantField: "timeouts.test",
+		},
+		{
+			name:      "invalid deadline",
+			cfg:       Config{Deadline: "tomorrow"},
+			wantField: "deadline",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vb := helpers.NewValidationBuilder()
+			validateTimeouts(vb, &tt.cfg)
+			resp := vb.Build()
+
+			if tt.wantField == "" {
+				if !resp.Valid {
+					t.Errorf("expected valid timeouts, got %+v", resp.Errors)
+				}
+				return
+			}
+			if resp.Valid || resp.Errors[0].Field != tt.wantField {
+				t.Errorf("expected error for %s, got %+v", tt.wantField, resp.Errors)
+			}
+		})
+	}
+}
+
+func TestTimeoutCause(t *testing.T) {
+	failed := errors.New("signal: killed")
+
+	ctx, cancel := withTimeout(context.Background(), time.Millisecond, errors.New("timed out after 1ms"))
+	defer cancel()
+	<-ctx.Done()
+	if got := timeoutCause(ctx, failed); got == nil || got.Error() != "timed out after 1ms" {
+		t.Errorf("expected timeout cause, got %v", got)
+	}
+	if got := timeoutCause(ctx, nil); got != nil {
+		t.Errorf("expected success to stay nil, got %v", got)
+	}
+
+	// Cancellation is not a timeout
+	ctx, cancel = withTimeout(context.Background(), 0, errors.New("unused"))
+	cancel()
+	if got := timeoutCause(ctx, failed); got != failed {
+		t.Errorf("expected original error, got %v", got)
+	}
+
+	// The deadline of an outer context is reported from inner ones
+	outer, cancelOuter := withDeadline(context.Background(), &Config{Deadline: "1ms"})
+	defer cancelOuter()
+	inner, cancelInner := withTimeout(outer, time.Hour, errors.New("timed out after 1h"))
+	defer cancelInner()
+	<-inner.Done()
+	if got := timeoutCause(inner, failed); got == nil || !strings.Contains(got.Error(), "deadline of 1ms exceeded") {
+		t.Errorf("expected deadline cause, got %v", got)
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/helpers"
+	"github.com/relicta-tech/relicta-plugin-sdk/plugin"
+	"gopkg.in/yaml.v3"
+)
+
+// Severity indicates how serious a validation issue is.
+type Severity string
+
+const (
+	// SeverityError blocks publishing.
+	SeverityError Severity = "error"
+	// SeverityWarning is reported but does not block publishing.
+	SeverityWarning Severity = "warning"
+	// SeverityInfo is a summary that needs no action.
+	SeverityInfo Severity = "info"
+)
+
+// ValidationIssue describes a single problem found in pubspec.yaml.
+type ValidationIssue struct {
+	// Field is the pubspec path of the offending value, e.g. "topics[1]",
+	// or the config key for issues in other files.
+	Field string
+	// File is the package file the issue refers to; empty means pubspec.yaml.
+	File string
+	// Rule is a stable identifier for the failed check.
+	Rule     string
+	Severity Severity
+	Message  string
+	// Line and Column locate the value in pubspec.yaml (1-based, 0 if unknown).
+	Line   int
+	Column int
+}
+
+// String formats the issue with its location when known.
+func (i ValidationIssue) String() string {
+	file := i.File
+	if file == "" {
+		file = "pubspec.yaml"
+	}
+	if i.Line > 0 {
+		return fmt.Sprintf("%s:%d:%d: %s", file, i.Line, i.Column, i.Message)
+	}
+	return i.Message
+}
+
+// configField returns the field name reported in the validation response.
+func (i ValidationIssue) configField() string {
+	if i.File == "" {
+		return "pubspec." + i.Field
+	}
+	return i.Field
+}
+
+// HasErrors reports whether any issue has error severity.
+func HasErrors(issues []ValidationIssue) bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// buildValidateResponse adds each error issue to the validation response.
+// The response has no notion of warnings and consumers treat every entry in
+// Errors as fatal, so warnings are logged instead.
+func buildValidateResponse(vb *helpers.ValidationBuilder, issues []ValidationIssue, logger *slog.Logger) *plugin.ValidateResponse {
+	for _, issue := range issues {
+		switch issue.Severity {
+		case SeverityError:
+			vb.AddErrorWithCode(issue.configField(), issue.String(), issue.Rule)
+		case SeverityWarning:
+			logger.Warn(issue.String(), "field", issue.configField(), "code", issue.Rule)
+		}
+	}
+
+	return vb.Build()
+}
+
+// issueList collects validation issues for a pubspec.
+type issueList struct {
+	pubspec *Pubspec
+	issues  []ValidationIssue
+}
+
+func (l *issueList) errorf(field, rule, format string, args ...any) {
+	l.add(SeverityError, field, rule, fmt.Sprintf(format, args...))
+}
+
+func (l *issueList) warnf(field, rule, format string, args ...any) {
+	l.add(SeverityWarning, field, rule, fmt.Sprintf(format, args...))
+}
+
+func (l *issueList) add(severity Severity, field, rule, message string) {
+	line, column := l.pubspec.position(field)
+	l.issues = append(l.issues, ValidationIssue{
+		Field:    field,
+		Rule:     rule,
+		Severity: severity,
+		Message:  message,
+		Line:     line,
+		Column:   column,
+	})
+}
+
+// position returns the line and column of the value at field in the parsed
+// YAML tree, falling back to the closest enclosing key when the value is missing.
+func (p *Pubspec) position(field string) (int, int) {
+	if p.node == nil || len(p.node.Content) == 0 {
+		return 0, 0
+	}
+
+	var line, column int
+	node := p.node.Content[0]
+	for _, segment := range fieldSegments(field) {
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == segment {
+					line, column = node.Content[i].Line, node.Content[i].Column
+					next = node.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			if idx, err := strconv.Atoi(segment); err == nil && idx >= 0 && idx < len(node.Content) {
+				next = node.Content[idx]
+				line, column = next.Line, next.Column
+			}
+		}
+		if next == nil {
+			break
+		}
+		node = next
+	}
+
+	return line, column
+}
+
+// fieldSegments splits a field path such as "screenshots[0].path" into
+// mapping keys and sequence indices.
+func fieldSegments(field string) []string {
+	var segments []string
+	for _, part := range strings.Split(field, ".") {
+		for part != "" {
+			open := strings.IndexByte(part, '[')
+			if open < 0 {
+				segments = append(segments, part)
+				break
+			}
+			if open > 0 {
+				segments = append(segments, part[:open])
+			}
+			end := strings.IndexByte(part[open:], ']')
+			if end < 0 {
+				break
+			}
+			segments = append(segments, part[open+1:open+end])
+			part = part[open+end+1:]
+		}
+	}
+	return segments
+}
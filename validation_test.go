@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/relicta-tech/relicta-plugin-sdk/helpers"
+)
+
+func TestFieldSegments(t *testing.T) {
+	tests := []struct {
+		field    string
+		expected []string
+	}{
+		{"name", []string{"name"}},
+		{"environment.sdk", []string{"environment", "sdk"}},
+		{"topics[1]", []string{"topics", "1"}},
+		{"screenshots[0].path", []string{"screenshots", "0", "path"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			if got := fieldSegments(tt.field); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestBuildValidateResponse(t *testing.T) {
+	tests := []struct {
+		name      string
+		issues    []ValidationIssue
+		wantValid bool
+		wantCount int
+		wantLog   string
+	}{
+		{
+			name:      "no issues",
+			wantValid: true,
+		},
+		{
+			name: "warnings only",
+			issues: []ValidationIssue{
+				{Field: "executables.tool", Rule: "executable-missing", Severity: SeverityWarning, Message: "missing"},
+			},
+			wantValid: true,
+			wantLog:   "code=executable-missing",
+		},
+		{
+			name: "errors and warnings",
+			issues: []ValidationIssue{
+				{Field: "version", Rule: "version-required", Severity: SeverityError, Message: "version is required"},
+				{Field: "topics[0]", Rule: "topic-format", Severity: SeverityError, Message: "bad topic", Line: 4, Column: 5},
+				{Field: "executables.tool", Rule: "executable-missing", Severity: SeverityWarning, Message: "missing"},
+			},
+			wantValid: false,
+			wantCount: 2,
+			wantLog:   "code=executable-missing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			resp := buildValidateResponse(helpers.NewValidationBuilder(), tt.issues, slog.New(slog.NewTextHandler(&logs, nil)))
+
+			if resp.Valid != tt.wantValid {
+				t.Errorf("expected valid %v, got %v", tt.wantValid, resp.Valid)
+			}
+			if resp.Valid != (len(resp.Errors) == 0) {
+				t.Errorf("expected valid to match the errors, got %v with %v", resp.Valid, resp.Errors)
+			}
+			if len(resp.Errors) != tt.wantCount {
+				t.Fatalf("expected %d entries, got %d", tt.wantCount, len(resp.Errors))
+			}
+			if !strings.Contains(logs.String(), tt.wantLog) || (tt.wantLog == "" && logs.Len() > 0) {
+				t.Errorf("expected warnings to be logged with %q, got %q", tt.wantLog, logs.String())
+			}
+
+			for _, e := range resp.Errors {
+				if !strings.HasPrefix(e.Field, "pubspec.") {
+					t.Errorf("expected pubspec field prefix, got %s", e.Field)
+				}
+				if e.Code == "" {
+					t.Errorf("expected rule code for %s", e.Field)
+				}
+				if e.Code == "topic-format" && e.Message != "pubspec.yaml:4:5: bad topic" {
+					t.Errorf("expected located message, got %s", e.Message)
+				}
+			}
+		})
+	}
+}